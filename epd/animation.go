@@ -0,0 +1,56 @@
+package epd
+
+import (
+	"errors"
+	"image"
+	"time"
+)
+
+// AnimationStats reports how a PlayFrames run kept up with its target rate.
+type AnimationStats struct {
+	Shown   int
+	Skipped int
+	FPS     float64
+}
+
+// PlayFrames shows frames in order at the target frame rate using partial
+// refreshes. Each frame is scheduled against the start time rather than the
+// previous frame, so the sleep before the next frame shrinks when a refresh
+// runs long. If playback falls more than a frame behind, late frames are
+// skipped instead of letting the whole animation drift. The achieved rate is
+// returned in the stats.
+func (d *Display) PlayFrames(frames []image.Image, fps float64) (AnimationStats, error) {
+	var stats AnimationStats
+	if fps <= 0 {
+		return stats, errors.New("frame rate must be positive")
+	}
+
+	interval := time.Duration(float64(time.Second) / fps)
+	start := d.clock.Now()
+	full := d.RequiredBounds()
+	for i, frame := range frames {
+		due := start.Add(time.Duration(i) * interval)
+		if i < len(frames)-1 && d.clock.Now().Sub(due) >= interval {
+			stats.Skipped++
+			continue
+		}
+
+		buf, err := d.encodeImage(frame)
+		if err != nil {
+			return stats, err
+		}
+		if err := d.refreshRegion(full, buf); err != nil {
+			return stats, err
+		}
+		stats.Shown++
+
+		if wait := due.Add(interval).Sub(d.clock.Now()); wait > 0 {
+			d.clock.Sleep(wait)
+		}
+	}
+
+	if elapsed := d.clock.Now().Sub(start); elapsed > 0 {
+		stats.FPS = float64(stats.Shown) / elapsed.Seconds()
+	}
+	return stats, nil
+}
@@ -0,0 +1,32 @@
+package epd
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Batch hands fn a portrait framebuffer seeded with the current frame (or
+// white if nothing has been drawn yet). Any image/draw operations fn performs
+// are encoded and pushed in a single full refresh once it returns. If fn
+// returns an error nothing is sent to the panel. The display stays locked
+// while fn runs, so fn must not call back into d.
+func (d *Display) Batch(fn func(dst draw.Image) error) error {
+	return d.withRecovery(func() error {
+		canvas := image.NewRGBA(d.RequiredBounds())
+		if d.frame != nil {
+			draw.Draw(canvas, canvas.Bounds(), d.frameToImage(d.frame), image.Point{}, draw.Src)
+		} else {
+			draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+		}
+
+		if err := fn(canvas); err != nil {
+			return err
+		}
+
+		buf, err := d.encodeImage(canvas)
+		if err != nil {
+			return err
+		}
+		return d.writeFrame(buf, false)
+	})
+}
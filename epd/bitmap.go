@@ -0,0 +1,236 @@
+package epd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+)
+
+// bitmap is a 1-bit image as stored in XBM and PBM files: rows of stride
+// bytes, a set bit meaning black. XBM packs the leftmost pixel into the
+// least significant bit, PBM (like the panel) into the most significant.
+type bitmap struct {
+	width, height int
+	stride        int
+	lsbFirst      bool
+	bits          []byte
+}
+
+// maxBitmapSide bounds the width and height a bitmap header may declare,
+// so stride*height cannot overflow and a short file cannot ask for a huge
+// allocation.
+const maxBitmapSide = 1 << 15
+
+func (b bitmap) black(x, y int) bool {
+	v := b.bits[y*b.stride+x/8]
+	if b.lsbFirst {
+		return v&(1<<uint(x%8)) != 0
+	}
+	return v&(1<<uint(7-x%8)) != 0
+}
+
+// DrawXBM draws an XBM bitmap with its top-left corner at at, in the
+// panel's portrait coordinates, and shows the result with a full refresh.
+// The rest of the last frame is kept. Set bits are drawn black and clear
+// bits white; pixels outside the panel are dropped.
+func (d *Display) DrawXBM(data []byte, at image.Point) error {
+	bm, err := parseXBM(data)
+	if err != nil {
+		return err
+	}
+	return d.drawBitmap(bm, at)
+}
+
+// DrawPBM is DrawXBM for a binary (P4) or plain (P1) portable bitmap.
+func (d *Display) DrawPBM(data []byte, at image.Point) error {
+	bm, err := parsePBM(data)
+	if err != nil {
+		return err
+	}
+	return d.drawBitmap(bm, at)
+}
+
+func (d *Display) drawBitmap(bm bitmap, at image.Point) error {
+	return d.withRecovery(func() error {
+		frame := d.retainedCopy()
+		d.drawBits(frame, bm, at)
+		return d.writeFrame(frame, d.landscape)
+	})
+}
+
+// drawBits draws bm into frame with its top-left corner at at.
+func (d *Display) drawBits(frame []byte, bm bitmap, at image.Point) {
+	lineWidth := d.LineWidth()
+	for y := 0; y < bm.height; y++ {
+		py := at.Y + y
+		if py < 0 || py >= d.height {
+			continue
+		}
+		row := py
+		if d.config.VerticalFlip {
+			row = d.height - 1 - py
+		}
+		for x := 0; x < bm.width; x++ {
+			px := at.X + x
+			if px < 0 || px >= d.width {
+				continue
+			}
+			mask := byte(1) << uint(7-px%8)
+			if bm.black(x, y) {
+				frame[row*lineWidth+px/8] &^= mask
+			} else {
+				frame[row*lineWidth+px/8] |= mask
+			}
+		}
+	}
+}
+
+// parseXBM reads the C source form of an X11 bitmap: the _width and
+// _height defines followed by a char (X11) or short (X10) array.
+func parseXBM(data []byte) (bitmap, error) {
+	src := string(data)
+	width, err := xbmDefine(src, "_width")
+	if err != nil {
+		return bitmap{}, err
+	}
+	height, err := xbmDefine(src, "_height")
+	if err != nil {
+		return bitmap{}, err
+	}
+
+	open := strings.IndexByte(src, '{')
+	end := strings.LastIndexByte(src, '}')
+	if open < 0 || end < open {
+		return bitmap{}, errors.New("xbm: missing bits array")
+	}
+	words := strings.Contains(src[:open], "short")
+
+	bm := bitmap{width: width, height: height, stride: (width + 7) / 8, lsbFirst: true}
+	if words {
+		bm.stride = (width + 15) / 16 * 2
+	}
+	for _, tok := range strings.Split(src[open+1:end], ",") {
+		tok = strings.TrimSpace(tok)
+		if tok == "" {
+			continue
+		}
+		if words {
+			v, err := strconv.ParseUint(tok, 0, 16)
+			if err != nil {
+				return bitmap{}, fmt.Errorf("xbm: invalid value %q", tok)
+			}
+			bm.bits = append(bm.bits, byte(v), byte(v>>8))
+			continue
+		}
+		v, err := strconv.ParseUint(tok, 0, 8)
+		if err != nil {
+			return bitmap{}, fmt.Errorf("xbm: invalid value %q", tok)
+		}
+		bm.bits = append(bm.bits, byte(v))
+	}
+	if len(bm.bits) < bm.stride*height {
+		return bitmap{}, fmt.Errorf("xbm: %d bytes of data, want %d for %dx%d", len(bm.bits), bm.stride*height, width, height)
+	}
+	return bm, nil
+}
+
+func xbmDefine(src, suffix string) (int, error) {
+	for _, line := range strings.Split(src, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "#define" && strings.HasSuffix(fields[1], suffix) {
+			v, err := strconv.Atoi(fields[2])
+			if err != nil || v <= 0 || v > maxBitmapSide {
+				return 0, fmt.Errorf("xbm: invalid %s %q", suffix[1:], fields[2])
+			}
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("xbm: missing %s define", suffix[1:])
+}
+
+// parsePBM reads a binary (P4) or plain (P1) portable bitmap.
+func parsePBM(data []byte) (bitmap, error) {
+	if len(data) < 2 || data[0] != 'P' || (data[1] != '4' && data[1] != '1') {
+		return bitmap{}, errors.New("pbm: invalid signature (want P1 or P4)")
+	}
+	plain := data[1] == '1'
+
+	rest := data[2:]
+	var dims [2]int
+	for i := range dims {
+		tok, next, err := pbmToken(rest)
+		if err != nil {
+			return bitmap{}, err
+		}
+		v, err := strconv.Atoi(tok)
+		if err != nil || v <= 0 || v > maxBitmapSide {
+			return bitmap{}, fmt.Errorf("pbm: invalid dimension %q", tok)
+		}
+		dims[i], rest = v, next
+	}
+
+	bm := bitmap{width: dims[0], height: dims[1], stride: (dims[0] + 7) / 8}
+	size := bm.stride * bm.height
+	if !plain {
+		// Exactly one whitespace byte separates the header from the raster.
+		if len(rest) == 0 {
+			return bitmap{}, errors.New("pbm: truncated header")
+		}
+		rest = rest[1:]
+		if len(rest) < size {
+			return bitmap{}, fmt.Errorf("pbm: %d bytes of data, want %d for %dx%d", len(rest), size, bm.width, bm.height)
+		}
+		bm.bits = rest[:size]
+		return bm, nil
+	}
+
+	// Every plain pixel takes at least one byte.
+	if len(rest) < bm.width*bm.height {
+		return bitmap{}, fmt.Errorf("pbm: %d bytes of data, want at least %d for %dx%d", len(rest), bm.width*bm.height, bm.width, bm.height)
+	}
+	bm.bits = make([]byte, size)
+	n := 0
+	for _, c := range rest {
+		if c == '0' || c == '1' {
+			if n == bm.width*bm.height {
+				break
+			}
+			if c == '1' {
+				x, y := n%bm.width, n/bm.width
+				bm.bits[y*bm.stride+x/8] |= 1 << uint(7-x%8)
+			}
+			n++
+		}
+	}
+	if n < bm.width*bm.height {
+		return bitmap{}, fmt.Errorf("pbm: %d pixels of data, want %d", n, bm.width*bm.height)
+	}
+	return bm, nil
+}
+
+// pbmToken returns the next whitespace-separated header token, skipping
+// '#' comments, and the data after it.
+func pbmToken(data []byte) (string, []byte, error) {
+	for {
+		data = bytes.TrimLeft(data, " \t\r\n")
+		if len(data) > 0 && data[0] == '#' {
+			if i := bytes.IndexByte(data, '\n'); i >= 0 {
+				data = data[i:]
+				continue
+			}
+			data = nil
+		}
+		break
+	}
+	end := bytes.IndexAny(data, " \t\r\n")
+	if end < 0 {
+		end = len(data)
+	}
+	if end == 0 {
+		return "", nil, errors.New("pbm: truncated header")
+	}
+	return string(data[:end]), data[end:], nil
+}
@@ -0,0 +1,43 @@
+package epd
+
+import "testing"
+
+func TestParsePBMRejectsHugeDimensions(t *testing.T) {
+	for _, data := range []string{
+		"P4 9223372036854775807 9223372036854775807\n",
+		"P1 4611686018427387904 4\n0",
+		"P4 40000 1\n",
+		"P1 100 100\n0101",
+	} {
+		if _, err := parsePBM([]byte(data)); err == nil {
+			t.Errorf("%q: parsed without error", data)
+		}
+	}
+}
+
+func TestParsePBM(t *testing.T) {
+	for _, data := range []string{
+		"P4\n# comment\n10 2\n\x80\x40\x01\xC0",
+		"P1\n10 2\n1000000001\n0000000011\n",
+	} {
+		bm, err := parsePBM([]byte(data))
+		if err != nil {
+			t.Fatalf("%q: %v", data, err)
+		}
+		if bm.width != 10 || bm.height != 2 {
+			t.Fatalf("%q: size %dx%d, want 10x2", data, bm.width, bm.height)
+		}
+		for _, p := range [][3]int{{0, 0, 1}, {1, 0, 0}, {9, 0, 1}, {8, 1, 1}, {9, 1, 1}, {0, 1, 0}} {
+			if got := bm.black(p[0], p[1]); got != (p[2] == 1) {
+				t.Errorf("%q: black(%d,%d) = %v", data, p[0], p[1], got)
+			}
+		}
+	}
+}
+
+func TestParseXBMRejectsHugeDimensions(t *testing.T) {
+	data := "#define x_width 9223372036854775807\n#define x_height 2\nstatic char x_bits[] = { 0x00 };\n"
+	if _, err := parseXBM([]byte(data)); err == nil {
+		t.Error("parsed without error")
+	}
+}
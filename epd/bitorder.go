@@ -0,0 +1,40 @@
+package epd
+
+import "math/bits"
+
+// BitOrder selects how the eight pixels of a frame buffer byte are sent to
+// the panel. Frame buffers (EncodeForDisplay, DrawBuffer) always keep the
+// leftmost pixel in the MSB; the order is applied as pixel data goes out,
+// so commands and their parameters are unaffected, unlike SPILSBFirst.
+type BitOrder int
+
+const (
+	// MSBFirst sends the leftmost pixel of each byte in bit 7, as the
+	// Waveshare panels expect.
+	MSBFirst BitOrder = iota
+	// LSBFirst sends the leftmost pixel in bit 0, for clones that otherwise
+	// show every 8-pixel block mirrored.
+	LSBFirst
+)
+
+// pixelBytes returns data in the configured BitOrder, copying it only when
+// the bits need reversing.
+func (d *Display) pixelBytes(data []byte) []byte {
+	if d.config.BitOrder != LSBFirst {
+		return data
+	}
+	out := append([]byte(nil), data...)
+	reversePixelBytes(LSBFirst, out)
+	return out
+}
+
+// reversePixelBytes puts data into order in place, for a buffer that is
+// not kept afterwards.
+func reversePixelBytes(order BitOrder, data []byte) {
+	if order != LSBFirst {
+		return
+	}
+	for i, b := range data {
+		data[i] = bits.Reverse8(b)
+	}
+}
@@ -0,0 +1,80 @@
+package epd
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"reflect"
+	"testing"
+)
+
+func TestBitOrder(t *testing.T) {
+	for _, tc := range []struct {
+		order     BitOrder
+		streaming bool
+		want      []byte
+	}{
+		{MSBFirst, false, []byte{0x7F, 0x3F}},
+		{LSBFirst, false, []byte{0xFE, 0xFC}},
+		{MSBFirst, true, []byte{0x7F, 0x3F}},
+		{LSBFirst, true, []byte{0xFE, 0xFC}},
+	} {
+		config := DefaultConfig()
+		config.BitOrder = tc.order
+		config.StreamingWrite = tc.streaming
+		d, rec, _, err := NewRecordingDisplay(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		initOps, err := rec.Ops()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec.Reset()
+
+		// Black pixels at x=0 and x=8,9 of the first row.
+		img := image.NewGray(d.RequiredBounds())
+		draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+		for _, x := range []int{0, 8, 9} {
+			img.SetGray(x, 0, color.Gray{})
+		}
+		if err := d.DrawImage(img); err != nil {
+			t.Fatal(err)
+		}
+
+		ops, err := rec.Ops()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ram []byte
+		for _, op := range ops {
+			if op.Command == cmdWriteRAM {
+				ram = op.Data
+			}
+		}
+		if len(ram) != d.BufferSize() {
+			t.Fatalf("%v streaming=%v: wrote %d RAM bytes, want %d", tc.order, tc.streaming, len(ram), d.BufferSize())
+		}
+		if !bytes.Equal(ram[:2], tc.want) {
+			t.Errorf("%v streaming=%v: first RAM bytes % X, want % X", tc.order, tc.streaming, ram[:2], tc.want)
+		}
+		if ram[2] != 0xFF {
+			t.Errorf("%v streaming=%v: white byte sent as %02X", tc.order, tc.streaming, ram[2])
+		}
+
+		// Command parameters are never reordered.
+		if tc.order == LSBFirst {
+			ref, refRec, _, err := NewRecordingDisplay(DefaultConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			refOps, _ := refRec.Ops()
+			ref.Close()
+			if !reflect.DeepEqual(initOps, refOps) {
+				t.Errorf("init sequence with LSBFirst:\n%v\nwant:\n%v", initOps, refOps)
+			}
+		}
+		d.Close()
+	}
+}
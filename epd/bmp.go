@@ -0,0 +1,157 @@
+package epd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// A minimal decoder for uncompressed Windows bitmaps, so BMP files can be
+// loaded without pulling in golang.org/x/image. It handles 1, 4, 8, 24 and
+// 32 bits per pixel, bottom-up or top-down. It is not registered with the
+// image package, so it never clashes with another BMP decoder; only
+// DrawImageReader uses it.
+
+// bmpMaxSide and bmpMaxPixels cap the size accepted from a header, since
+// the decoder allocates the whole image before reading any pixel data.
+const (
+	bmpMaxSide   = 1 << 15
+	bmpMaxPixels = 1 << 26
+)
+
+type bmpHeader struct {
+	offset  uint32
+	width   int
+	height  int
+	topDown bool
+	bpp     int
+	colors  int
+}
+
+func readBMPHeader(r io.Reader) (bmpHeader, []byte, error) {
+	var h bmpHeader
+	var file [14]byte
+	if _, err := io.ReadFull(r, file[:]); err != nil {
+		return h, nil, err
+	}
+	if file[0] != 'B' || file[1] != 'M' {
+		return h, nil, errors.New("bmp: invalid signature")
+	}
+	h.offset = binary.LittleEndian.Uint32(file[10:])
+
+	var info [40]byte
+	if _, err := io.ReadFull(r, info[:4]); err != nil {
+		return h, nil, err
+	}
+	size := binary.LittleEndian.Uint32(info[:4])
+	if size < 40 {
+		return h, nil, fmt.Errorf("bmp: unsupported header size %d", size)
+	}
+	if _, err := io.ReadFull(r, info[4:]); err != nil {
+		return h, nil, err
+	}
+	if size > 40 {
+		if _, err := io.CopyN(io.Discard, r, int64(size-40)); err != nil {
+			return h, nil, err
+		}
+	}
+
+	h.width = int(int32(binary.LittleEndian.Uint32(info[4:])))
+	h.height = int(int32(binary.LittleEndian.Uint32(info[8:])))
+	h.bpp = int(binary.LittleEndian.Uint16(info[14:]))
+	compression := binary.LittleEndian.Uint32(info[16:])
+	h.colors = int(binary.LittleEndian.Uint32(info[32:]))
+	if h.height < 0 {
+		h.height = -h.height
+		h.topDown = true
+	}
+	if h.width <= 0 || h.height <= 0 {
+		return h, nil, fmt.Errorf("bmp: invalid size %dx%d", h.width, h.height)
+	}
+	if h.width > bmpMaxSide || h.height > bmpMaxSide || h.width*h.height > bmpMaxPixels {
+		return h, nil, fmt.Errorf("bmp: size %dx%d too large", h.width, h.height)
+	}
+	// BI_BITFIELDS is accepted for 32 bpp as long as the masks are the usual
+	// BGRA layout, which is what every common encoder writes.
+	if compression != 0 && !(compression == 3 && h.bpp == 32) {
+		return h, nil, fmt.Errorf("bmp: unsupported compression %d", compression)
+	}
+
+	var palette []byte
+	switch h.bpp {
+	case 1, 4, 8:
+		if h.colors == 0 {
+			h.colors = 1 << uint(h.bpp)
+		}
+		if h.colors < 0 || h.colors > 1<<uint(h.bpp) {
+			return h, nil, fmt.Errorf("bmp: %d palette colors for %d bpp", h.colors, h.bpp)
+		}
+		palette = make([]byte, 4*h.colors)
+		if _, err := io.ReadFull(r, palette); err != nil {
+			return h, nil, err
+		}
+	case 24, 32:
+	default:
+		return h, nil, fmt.Errorf("bmp: unsupported bit depth %d", h.bpp)
+	}
+
+	read := uint32(14) + size + uint32(len(palette))
+	if h.offset > read {
+		if _, err := io.CopyN(io.Discard, r, int64(h.offset-read)); err != nil {
+			return h, nil, err
+		}
+	}
+	return h, palette, nil
+}
+
+func decodeBMPConfig(r io.Reader) (image.Config, error) {
+	h, _, err := readBMPHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: color.RGBAModel, Width: h.width, Height: h.height}, nil
+}
+
+func decodeBMP(r io.Reader) (image.Image, error) {
+	h, palette, err := readBMPHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, h.width, h.height))
+	stride := ((h.width*h.bpp + 31) / 32) * 4
+	row := make([]byte, stride)
+	for i := 0; i < h.height; i++ {
+		if _, err := io.ReadFull(r, row); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		y := h.height - 1 - i
+		if h.topDown {
+			y = i
+		}
+		for x := 0; x < h.width; x++ {
+			var c color.RGBA
+			switch h.bpp {
+			case 24, 32:
+				p := row[x*h.bpp/8:]
+				c = color.RGBA{R: p[2], G: p[1], B: p[0], A: 0xFF}
+			default:
+				bit := x * h.bpp
+				idx := int(row[bit/8]>>uint(8-h.bpp-bit%8)) & (1<<uint(h.bpp) - 1)
+				if idx >= h.colors {
+					return nil, fmt.Errorf("bmp: palette index %d out of range", idx)
+				}
+				p := palette[4*idx:]
+				c = color.RGBA{R: p[2], G: p[1], B: p[0], A: 0xFF}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img, nil
+}
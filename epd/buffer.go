@@ -0,0 +1,49 @@
+package epd
+
+import (
+	"fmt"
+	"image"
+)
+
+// EncodeForDisplay converts img to the exact bytes DrawImage would write to
+// the panel RAM, using this display's size, pipeline, oversize handling and
+// pixel mapping, without sending anything. The result can be passed to
+// DrawBuffer, possibly on another device with the same configuration.
+func (d *Display) EncodeForDisplay(img image.Image) ([]byte, error) {
+	buf, _, err := d.encodeForDisplay(img)
+	return buf, err
+}
+
+// encodeForDisplay is EncodeForDisplay that also reports whether img is
+// drawn in landscape, for showFrame.
+func (d *Display) encodeForDisplay(img image.Image) ([]byte, bool, error) {
+	return d.encodeOriented(d.prepareImage(img), orientAuto)
+}
+
+// DrawBuffer writes a pre-encoded frame buffer to the panel and performs a
+// full refresh. buf must be exactly one frame as produced by
+// EncodeForDisplay; it is copied, so the caller may reuse it.
+func (d *Display) DrawBuffer(buf []byte) error {
+	if len(buf) != d.BufferSize() {
+		return fmt.Errorf("buffer length %d does not match frame size %d", len(buf), d.BufferSize())
+	}
+
+	frame := make([]byte, len(buf))
+	copy(frame, buf)
+	return d.showBuffer(frame)
+}
+
+// DrawImageCached draws img with a full refresh like DrawImage and also
+// returns the encoded frame, so repeated screens can be cached and shown
+// again with DrawBuffer without re-encoding. The returned slice is the
+// caller's own copy.
+func (d *Display) DrawImageCached(img image.Image) ([]byte, error) {
+	buf, landscape, err := d.encodeForDisplay(img)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.showFrame(buf, landscape); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), buf...), nil
+}
@@ -0,0 +1,78 @@
+package epd
+
+import (
+	"fmt"
+
+	periphconn "periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+)
+
+// checkBulkFrequency validates BulkSPIFrequency against the model's rated
+// SPI clock. The port's own limit cannot be queried, so that is left to
+// Connect and LimitSpeed when the bus is opened.
+func checkBulkFrequency(config DisplayConfig) error {
+	f := config.BulkSPIFrequency
+	if f == 0 {
+		return nil
+	}
+	if f < 0 {
+		return fmt.Errorf("invalid bulk SPI frequency %s", f)
+	}
+	if config.SoftSPI {
+		return fmt.Errorf("bulk SPI frequency is not supported with software SPI")
+	}
+	if spec, ok := Models[config.Model]; ok && spec.MaxSPIFrequency > 0 && f > spec.MaxSPIFrequency {
+		return fmt.Errorf("bulk SPI frequency %s exceeds the %v maximum of %s", f, config.Model, spec.MaxSPIFrequency)
+	}
+	return nil
+}
+
+// connectFrequency is the clock the port is connected at. With
+// BulkSPIFrequency set it is the faster of the two rates, and LimitSpeed
+// brings each transfer down to the rate it needs, since a port can only be
+// connected once and a second handle on the same bus is not portable.
+func connectFrequency(config DisplayConfig) physic.Frequency {
+	if config.BulkSPIFrequency > config.SPIFrequency {
+		return config.BulkSPIFrequency
+	}
+	return config.SPIFrequency
+}
+
+// limitCommandSpeed drops the port back to SPIFrequency for commands after
+// it was connected, or a bulk write ran, at BulkSPIFrequency.
+func (d *Display) limitCommandSpeed() error {
+	if d.config.BulkSPIFrequency == 0 {
+		return nil
+	}
+	if err := d.port.LimitSpeed(d.config.SPIFrequency); err != nil {
+		return fmt.Errorf("SPI speed limit %s failed: %w", d.config.SPIFrequency, err)
+	}
+	return nil
+}
+
+// txBulk sends w split to the transfer size limit, at BulkSPIFrequency when
+// it is set.
+func (d *Display) txBulk(w []byte) error {
+	f := d.config.BulkSPIFrequency
+	if f == 0 {
+		return d.tx(w)
+	}
+	if err := d.port.LimitSpeed(f); err != nil {
+		return fmt.Errorf("bulk SPI speed %s failed: %w", f, err)
+	}
+	if err := d.tx(w); err != nil {
+		if limitErr := d.limitCommandSpeed(); limitErr != nil {
+			return fmt.Errorf("%w (restoring the command speed failed: %v)", err, limitErr)
+		}
+		return err
+	}
+	return d.limitCommandSpeed()
+}
+
+func connMaxTxSize(conn spi.Conn) int {
+	if limits, ok := conn.(periphconn.Limits); ok {
+		return limits.MaxTxSize()
+	}
+	return 0
+}
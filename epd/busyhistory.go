@@ -0,0 +1,81 @@
+package epd
+
+import (
+	"sync"
+	"time"
+)
+
+// BusyEvent is one observed change of the BUSY pin. Busy reports the new
+// state, already translated for the controller's active level.
+type BusyEvent struct {
+	Time time.Time
+	Busy bool
+}
+
+// busyRing keeps the most recent BUSY transitions. A zero-capacity ring
+// records nothing, so the history costs nothing unless it is enabled.
+type busyRing struct {
+	mu     sync.Mutex
+	events []BusyEvent
+	next   int
+	full   bool
+	last   bool
+	seen   bool
+}
+
+func newBusyRing(size int) *busyRing {
+	if size < 0 {
+		size = 0
+	}
+	return &busyRing{events: make([]BusyEvent, size)}
+}
+
+// observe records busy at t if it differs from the last recorded state, so
+// repeated polls of an unchanged pin do not flood the ring.
+func (r *busyRing) observe(t time.Time, busy bool) {
+	if len(r.events) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.seen && r.last == busy {
+		return
+	}
+	r.last, r.seen = busy, true
+
+	r.events[r.next] = BusyEvent{Time: t, Busy: busy}
+	r.next++
+	if r.next == len(r.events) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+func (r *busyRing) snapshot() []BusyEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]BusyEvent(nil), r.events[:r.next]...)
+	}
+	out := make([]BusyEvent, 0, len(r.events))
+	out = append(out, r.events[r.next:]...)
+	return append(out, r.events[:r.next]...)
+}
+
+// BusyHistory returns the recorded BUSY transitions, oldest first. It holds
+// at most BusyHistorySize events and is empty when the history is disabled.
+func (d *Display) BusyHistory() []BusyEvent {
+	return d.busyHistory.snapshot()
+}
+
+// readBusy samples the BUSY pin, records any transition, and reports whether
+// the controller is busy.
+func (d *Display) readBusy() bool {
+	busy := d.busy.Read() == d.busyActiveLevel()
+	if d.config.BusyHistorySize > 0 {
+		d.busyHistory.observe(d.clock.Now(), busy)
+	}
+	return busy
+}
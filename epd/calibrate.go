@@ -0,0 +1,121 @@
+package epd
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Corner is where the calibration marker appears, as seen on the panel.
+type Corner int
+
+const (
+	CornerTopLeft Corner = iota
+	CornerTopRight
+	CornerBottomLeft
+	CornerBottomRight
+)
+
+// Direction is the way the calibration arrow points, as seen on the panel.
+type Direction int
+
+const (
+	DirectionUp Direction = iota
+	DirectionRight
+	DirectionDown
+	DirectionLeft
+)
+
+// Calibration holds the mount-dependent settings derived by Calibrate.
+// Together the mirroring and the rotation cover every mount, including
+// panels turned by a quarter.
+type Calibration struct {
+	DataEntry DataEntry
+	Rotation  Rotation
+}
+
+// Apply copies the calibrated settings into config. A quarter-turn mount
+// appends a StageRotate to the pipeline, so images are drawn upright at
+// the size the panel is viewed at; apply it once per config.
+func (c Calibration) Apply(config *DisplayConfig) {
+	config.DataEntry = c.DataEntry
+	if c.Rotation != Rotate0 {
+		config.Pipeline = append(config.Pipeline, StageRotate(c.Rotation))
+	}
+}
+
+const (
+	calibrationMargin = 4
+	calibrationMarker = 24
+)
+
+// Calibrate shows a marker in what the driver currently considers the
+// top-left corner and an arrow from the center pointing at what it
+// considers the top, then calls seen to learn which corner the marker
+// appears in and which way the arrow points when the panel is viewed the
+// right way up. The corner alone cannot tell a mirrored panel from one
+// mounted at 90 degrees; the arrow settles it. It returns the data entry
+// and rotation that show images upright. Persist the result and apply it
+// to the config on later runs; it does not change the running Display.
+func (d *Display) Calibrate(seen func() (Corner, Direction, error)) (Calibration, error) {
+	bounds := d.RequiredBounds()
+	canvas := image.NewGray(bounds)
+	draw.Draw(canvas, bounds, image.White, image.Point{}, draw.Src)
+
+	m := calibrationMargin
+	corner := image.Rect(m, m, m+calibrationMarker, m+calibrationMarker)
+	draw.Draw(canvas, image.Rect(corner.Min.X, corner.Min.Y, corner.Max.X, corner.Min.Y+4), image.Black, image.Point{}, draw.Src)
+	draw.Draw(canvas, image.Rect(corner.Min.X, corner.Min.Y, corner.Min.X+4, corner.Max.Y), image.Black, image.Point{}, draw.Src)
+
+	center := image.Pt(bounds.Dx()/2, bounds.Dy()/2)
+	tip := image.Pt(center.X, center.Y-bounds.Dy()/4)
+	for o := -1; o <= 1; o++ {
+		drawLine(canvas, center.X+o, center.Y, tip.X+o, tip.Y, color.Black)
+	}
+	drawLine(canvas, tip.X, tip.Y, tip.X-10, tip.Y+10, color.Black)
+	drawLine(canvas, tip.X, tip.Y, tip.X+10, tip.Y+10, color.Black)
+
+	buf, err := d.encodeImage(canvas)
+	if err != nil {
+		return Calibration{}, err
+	}
+	if err := d.showFrame(buf, false); err != nil {
+		return Calibration{}, err
+	}
+
+	c, dir, err := seen()
+	if err != nil {
+		return Calibration{}, err
+	}
+	fix, rotation, err := calibrationFix(c, dir)
+	if err != nil {
+		return Calibration{}, err
+	}
+	return Calibration{DataEntry: d.config.DataEntry ^ fix, Rotation: rotation}, nil
+}
+
+// calibrationFix returns the mirroring and rotation that undo the mount in
+// which the top-left corner shows up at c and the top points to dir. Only
+// the pairs where dir runs along an edge meeting at c are possible.
+func calibrationFix(c Corner, dir Direction) (DataEntry, Rotation, error) {
+	switch {
+	case c == CornerTopLeft && dir == DirectionUp:
+		return DataEntryNormal, Rotate0, nil
+	case c == CornerTopRight && dir == DirectionUp:
+		return DataEntryMirrorX, Rotate0, nil
+	case c == CornerBottomLeft && dir == DirectionDown:
+		return DataEntryMirrorY, Rotate0, nil
+	case c == CornerBottomRight && dir == DirectionDown:
+		return DataEntryUpsideDown, Rotate0, nil
+	case c == CornerTopRight && dir == DirectionRight:
+		return DataEntryNormal, Rotate270, nil
+	case c == CornerBottomLeft && dir == DirectionLeft:
+		return DataEntryNormal, Rotate90, nil
+	case c == CornerTopLeft && dir == DirectionLeft:
+		return DataEntryMirrorX, Rotate90, nil
+	case c == CornerBottomRight && dir == DirectionRight:
+		return DataEntryMirrorY, Rotate90, nil
+	}
+	return 0, 0, fmt.Errorf("marker in corner %d cannot have the arrow pointing %d", c, dir)
+}
@@ -0,0 +1,111 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestCalibrationFix mounts an n x n panel in each of the eight possible
+// ways, reports where the marker and arrow would be seen, and checks that
+// the returned mirroring and rotation show an asymmetric image upright.
+func TestCalibrationFix(t *testing.T) {
+	const n = 5
+	mounts := map[string]func(x, y int) (int, int){
+		"upright":        func(x, y int) (int, int) { return x, y },
+		"mirror x":       func(x, y int) (int, int) { return n - 1 - x, y },
+		"mirror y":       func(x, y int) (int, int) { return x, n - 1 - y },
+		"upside down":    func(x, y int) (int, int) { return n - 1 - x, n - 1 - y },
+		"clockwise":      func(x, y int) (int, int) { return n - 1 - y, x },
+		"anticlockwise":  func(x, y int) (int, int) { return y, n - 1 - x },
+		"transposed":     func(x, y int) (int, int) { return y, x },
+		"antitransposed": func(x, y int) (int, int) { return n - 1 - y, n - 1 - x },
+	}
+
+	img := image.NewGray(image.Rect(0, 0, n, n))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i)
+	}
+
+	for name, mount := range mounts {
+		var c Corner
+		switch x, y := mount(0, 0); {
+		case x == 0 && y == 0:
+			c = CornerTopLeft
+		case y == 0:
+			c = CornerTopRight
+		case x == 0:
+			c = CornerBottomLeft
+		default:
+			c = CornerBottomRight
+		}
+		var dir Direction
+		cx, cy := mount(n/2, n/2)
+		switch tx, ty := mount(n/2, 0); {
+		case ty < cy:
+			dir = DirectionUp
+		case tx > cx:
+			dir = DirectionRight
+		case ty > cy:
+			dir = DirectionDown
+		default:
+			dir = DirectionLeft
+		}
+
+		fix, rotation, err := calibrationFix(c, dir)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		rotated := StageRotate(rotation)(img)
+		viewed := image.NewGray(img.Rect)
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				bx, by := x, y
+				if fix.mirrorX() {
+					bx = n - 1 - x
+				}
+				if fix.mirrorY() {
+					by = n - 1 - y
+				}
+				vx, vy := mount(bx, by)
+				viewed.Set(vx, vy, rotated.At(x, y))
+			}
+		}
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				if got, want := viewed.GrayAt(x, y), img.GrayAt(x, y); got != want {
+					t.Fatalf("%s: got %v at (%d,%d) with %v and rotation %d, want %v", name, got, x, y, fix, rotation, want)
+				}
+			}
+		}
+	}
+
+	if _, _, err := calibrationFix(CornerTopLeft, DirectionRight); err == nil {
+		t.Error("impossible marker and arrow pair accepted")
+	}
+}
+
+func TestStageRotate(t *testing.T) {
+	img := image.NewGray(image.Rect(10, 10, 13, 12))
+	img.SetGray(10, 10, color.Gray{Y: 1})
+	for _, tc := range []struct {
+		r    Rotation
+		size image.Point
+		at   image.Point
+	}{
+		{Rotate0, image.Pt(3, 2), image.Pt(10, 10)},
+		{Rotate90, image.Pt(2, 3), image.Pt(1, 0)},
+		{Rotate180, image.Pt(3, 2), image.Pt(2, 1)},
+		{Rotate270, image.Pt(2, 3), image.Pt(0, 2)},
+	} {
+		out := StageRotate(tc.r)(img)
+		if got := out.Bounds().Size(); got != tc.size {
+			t.Errorf("rotation %d: size %v, want %v", tc.r, got, tc.size)
+			continue
+		}
+		if y := color.GrayModel.Convert(out.At(tc.at.X, tc.at.Y)).(color.Gray).Y; y != 1 {
+			t.Errorf("rotation %d: top-left pixel not at %v", tc.r, tc.at)
+		}
+	}
+}
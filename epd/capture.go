@@ -0,0 +1,95 @@
+package epd
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+func (d *Display) bufferToImage(buf []byte) *image.Gray {
+	lineWidth := d.LineWidth()
+	img := image.NewGray(image.Rect(0, 0, d.width, d.height))
+	for y := 0; y < d.height; y++ {
+		for x := 0; x < d.width; x++ {
+			if buf[x/8+y*lineWidth]&(1<<uint(7-x%8)) != 0 {
+				img.SetGray(x, y, color.Gray{Y: 0xFF})
+			}
+		}
+	}
+	return img
+}
+
+// frameToImage converts a retained frame to a top-down image, undoing
+// VerticalFlip on a copy.
+func (d *Display) frameToImage(frame []byte) *image.Gray {
+	buf := append([]byte(nil), frame...)
+	d.flipVertical(buf)
+	return d.bufferToImage(buf)
+}
+
+// captureFrame writes buf, a frame as it is sent to RAM, to CaptureDir as
+// a PNG, with VerticalFlip undone so the image is the right way up.
+func (d *Display) captureFrame(buf []byte) error {
+	if d.config.CaptureDir == "" {
+		return nil
+	}
+
+	name := fmt.Sprintf("frame-%s.png", d.clock.Now().Format("20060102-150405.000000000"))
+	f, err := os.Create(filepath.Join(d.config.CaptureDir, name))
+	if err != nil {
+		return fmt.Errorf("frame capture failed: %w", err)
+	}
+	if err := png.Encode(f, d.frameToImage(buf)); err != nil {
+		f.Close()
+		return fmt.Errorf("frame capture encode failed: %w", err)
+	}
+	return f.Close()
+}
+
+// SaveFrame writes the last displayed frame to path as a PNG, in the
+// orientation it was drawn in: a landscape image comes back landscape, and
+// VerticalFlip and content inversion are undone or applied so the file
+// matches what the panel shows. It returns ErrNoFrame if nothing has been
+// drawn yet.
+func (d *Display) SaveFrame(path string) error {
+	d.opMu.Lock()
+	if d.frame == nil {
+		d.opMu.Unlock()
+		return ErrNoFrame
+	}
+	buf := append([]byte(nil), d.frame...)
+	landscape, inverted := d.landscape, d.inverted
+	d.opMu.Unlock()
+
+	d.flipVertical(buf)
+	if inverted {
+		for i := range buf {
+			buf[i] = ^buf[i]
+		}
+	}
+
+	var img image.Image = d.bufferToImage(buf)
+	if landscape {
+		portrait := img.(*image.Gray)
+		rotated := image.NewGray(image.Rect(0, 0, d.height, d.width))
+		for y := 0; y < d.width; y++ {
+			for x := 0; x < d.height; x++ {
+				rotated.SetGray(x, y, portrait.GrayAt(y, d.height-1-x))
+			}
+		}
+		img = rotated
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("save frame failed: %w", err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("save frame encode failed: %w", err)
+	}
+	return f.Close()
+}
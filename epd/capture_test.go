@@ -0,0 +1,40 @@
+package epd
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCaptureFrameVerticalFlip(t *testing.T) {
+	config := DefaultConfig()
+	config.VerticalFlip = true
+	config.CaptureDir = t.TempDir()
+	d := newDryRunDisplay(t, config)
+
+	img := whiteImage(d)
+	draw.Draw(img, image.Rect(0, 0, 8, 1), image.Black, image.Point{}, draw.Src)
+	if err := d.DrawImage(img); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(config.CaptureDir, "*.png"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("got captures %v (%v), want one", files, err)
+	}
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	captured, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _, _, _ := captured.At(0, 0).RGBA(); r != 0 {
+		t.Error("the capture is upside down: the black top row is not at the top")
+	}
+}
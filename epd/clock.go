@@ -0,0 +1,26 @@
+package epd
+
+import "time"
+
+// clock abstracts the time functions used by the reset, busy-wait and retry
+// logic so tests can drive them without real delays.
+type clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// clockFor returns the clock a config selects: the real clock unless a test
+// set DisplayConfig.clock.
+func clockFor(config DisplayConfig) clock {
+	if config.clock != nil {
+		return config.clock
+	}
+	return realClock{}
+}
@@ -0,0 +1,36 @@
+package epd
+
+import (
+	"image"
+	"image/draw"
+	"strings"
+	"time"
+)
+
+const (
+	clockMargin   = 4
+	clockMaxScale = 10
+)
+
+// DrawClock renders t formatted with the Go time layout format, as large
+// as fits and centered on a landscape canvas. Successive calls use partial
+// refreshes; every ClockFullRefreshEvery calls (and the first) a full
+// refresh clears accumulated ghosting. Multi-line layouts may separate lines
+// with '\n'.
+func (d *Display) DrawClock(t time.Time, format string) error {
+	bounds := image.Rect(0, 0, d.height, d.width)
+	canvas := image.NewGray(bounds)
+	draw.Draw(canvas, bounds, image.White, image.Point{}, draw.Src)
+	drawCenteredLines(canvas, bounds.Inset(clockMargin), strings.Split(t.Format(format), "\n"), clockMaxScale)
+
+	every := d.config.ClockFullRefreshEvery
+	if d.clockTicks == 0 || (every > 0 && d.clockTicks%every == 0) {
+		if err := d.DrawLandscape(canvas); err != nil {
+			return err
+		}
+	} else if err := d.UpdateRegion(bounds, canvas); err != nil {
+		return err
+	}
+	d.clockTicks++
+	return nil
+}
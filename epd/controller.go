@@ -0,0 +1,260 @@
+package epd
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/physic"
+)
+
+type Model int
+
+const (
+	ModelSSD1680 Model = iota
+	ModelUC8151
+	// ModelSSD1680Red is the black/white/red SSD1680 panel (Waveshare
+	// 2.13" B). Its red plane occupies the bank black/white panels use for
+	// partial refresh, so it only supports full refreshes.
+	ModelSSD1680Red
+)
+
+func (m Model) String() string {
+	switch m {
+	case ModelSSD1680:
+		return "SSD1680"
+	case ModelUC8151:
+		return "UC8151"
+	case ModelSSD1680Red:
+		return "SSD1680 (B/W/R)"
+	default:
+		return fmt.Sprintf("Model(%d)", int(m))
+	}
+}
+
+type controller interface {
+	init() error
+	initSteps() ([]InitStep, error)
+	beginWriteRAM() error
+	update() error
+	updatePartial(region image.Rectangle, oldBuf, newBuf []byte) error
+	standby() error
+	setBorder(border BorderColor) error
+	setInvert(invert bool) error
+	powerOn() error
+	sleep() error
+	busyLevel() gpio.Level
+}
+
+// oldBankWriter is implemented by controllers whose previous-frame RAM bank
+// is separately addressable and would otherwise keep stale content.
+type oldBankWriter interface {
+	beginWriteOldRAM() error
+}
+
+type ModelSpec struct {
+	Width             int
+	Height            int
+	GateCount         int
+	MaxWidth          int
+	MaxHeight         int
+	SupportsRed       bool
+	SupportsGrayscale bool
+	SupportsPartial   bool
+	SupportsFast      bool
+	HasTempSensor     bool
+	MaxSPIFrequency   physic.Frequency
+
+	// WidthMM and HeightMM are the active area in portrait orientation.
+	WidthMM  float64
+	HeightMM float64
+
+	FullRefreshTime    time.Duration
+	FastRefreshTime    time.Duration
+	PartialRefreshTime time.Duration
+}
+
+type Capabilities struct {
+	Grayscale4        bool
+	TriColor          bool
+	PartialRefresh    bool
+	FastRefresh       bool
+	TemperatureSensor bool
+}
+
+var Models = map[Model]ModelSpec{
+	ModelSSD1680: {
+		Width:           122,
+		Height:          250,
+		GateCount:       250,
+		MaxWidth:        176,
+		MaxHeight:       296,
+		SupportsPartial: true,
+		HasTempSensor:   true,
+		MaxSPIFrequency: 20 * physic.MegaHertz,
+		WidthMM:         23.71,
+		HeightMM:        48.55,
+
+		FullRefreshTime:    2 * time.Second,
+		PartialRefreshTime: 300 * time.Millisecond,
+	},
+	ModelUC8151: {
+		Width:           104,
+		Height:          212,
+		GateCount:       212,
+		MaxWidth:        160,
+		MaxHeight:       296,
+		SupportsPartial: true,
+		HasTempSensor:   true,
+		MaxSPIFrequency: 10 * physic.MegaHertz,
+		WidthMM:         23.70,
+		HeightMM:        48.25,
+
+		FullRefreshTime:    4 * time.Second,
+		PartialRefreshTime: 500 * time.Millisecond,
+	},
+	ModelSSD1680Red: {
+		Width:           122,
+		Height:          250,
+		GateCount:       250,
+		MaxWidth:        176,
+		MaxHeight:       296,
+		SupportsRed:     true,
+		HasTempSensor:   true,
+		MaxSPIFrequency: 20 * physic.MegaHertz,
+		WidthMM:         23.71,
+		HeightMM:        48.55,
+
+		FullRefreshTime: 16 * time.Second,
+	},
+}
+
+func ModelInfo(m Model) (ModelSpec, bool) {
+	spec, ok := Models[m]
+	return spec, ok
+}
+
+// PhysicalSize returns the active area of the panel in millimeters, in
+// portrait orientation, and its resolution in dots per inch. All values are
+// zero when the model has no known dimensions or a custom size is
+// configured.
+func (d *Display) PhysicalSize() (widthMM, heightMM, dpi float64) {
+	spec, ok := Models[d.config.Model]
+	if !ok || spec.WidthMM == 0 || d.width != spec.Width || d.height != spec.Height {
+		return 0, 0, 0
+	}
+	return spec.WidthMM, spec.HeightMM, float64(d.width) / (spec.WidthMM / 25.4)
+}
+
+func (d *Display) Capabilities() Capabilities {
+	spec, _ := ModelInfo(d.config.Model)
+	return Capabilities{
+		Grayscale4:        spec.SupportsGrayscale,
+		TriColor:          spec.SupportsRed,
+		PartialRefresh:    spec.SupportsPartial,
+		FastRefresh:       spec.SupportsFast,
+		TemperatureSensor: spec.HasTempSensor,
+	}
+}
+
+type RefreshMode int
+
+const (
+	RefreshFull RefreshMode = iota
+	RefreshFast
+	RefreshPartial
+)
+
+// EstimateRefreshTime returns the typical duration of a refresh in the given
+// mode on the configured model, taken from the model registry. It is meant
+// for scheduling decisions; actual times vary with temperature. Modes the
+// model does not support fall back to the full refresh estimate.
+func (d *Display) EstimateRefreshTime(mode RefreshMode) time.Duration {
+	spec, _ := ModelInfo(d.config.Model)
+	switch {
+	case mode == RefreshFast && spec.SupportsFast && spec.FastRefreshTime > 0:
+		return spec.FastRefreshTime
+	case mode == RefreshPartial && spec.SupportsPartial && spec.PartialRefreshTime > 0:
+		return spec.PartialRefreshTime
+	default:
+		return spec.FullRefreshTime
+	}
+}
+
+func modelSize(model Model) (int, int, error) {
+	spec, ok := ModelInfo(model)
+	if !ok {
+		return 0, 0, fmt.Errorf("unsupported display model: %v", model)
+	}
+	return spec.Width, spec.Height, nil
+}
+
+// panelSize returns the active area for config: the model's catalog size,
+// or CustomWidth x CustomHeight for cut or non-standard panels, checked
+// against what the controller can address.
+func panelSize(config DisplayConfig) (int, int, error) {
+	width, height, err := modelSize(config.Model)
+	if err != nil {
+		return 0, 0, err
+	}
+	if config.CustomWidth == 0 && config.CustomHeight == 0 {
+		return width, height, nil
+	}
+
+	spec := Models[config.Model]
+	if config.CustomWidth <= 0 || config.CustomWidth > spec.MaxWidth {
+		return 0, 0, fmt.Errorf("custom width %d out of range 1..%d for %v", config.CustomWidth, spec.MaxWidth, config.Model)
+	}
+	if config.CustomHeight <= 0 || config.CustomHeight > spec.MaxHeight {
+		return 0, 0, fmt.Errorf("custom height %d out of range 1..%d for %v", config.CustomHeight, spec.MaxHeight, config.Model)
+	}
+	return config.CustomWidth, config.CustomHeight, nil
+}
+
+// physicalRegion maps a byte-aligned region of the frame onto the panel
+// area it lands on under the configured DataEntry mirroring, for partial
+// windows.
+func (d *Display) physicalRegion(r image.Rectangle) image.Rectangle {
+	if d.config.DataEntry.mirrorX() {
+		w := d.LineWidth() * 8
+		r.Min.X, r.Max.X = w-r.Max.X, w-r.Min.X
+	}
+	if d.config.DataEntry.mirrorY() {
+		r.Min.Y, r.Max.Y = d.height-r.Max.Y, d.height-r.Min.Y
+	}
+	return r
+}
+
+// checkOrigin validates OriginX and OriginY for a width x height panel:
+// the UC8151 has no RAM window to shift, the SSD1680 addresses X in bytes,
+// and the shifted window must fit the controller RAM.
+func checkOrigin(config DisplayConfig, width, height int) error {
+	x, y := config.OriginX, config.OriginY
+	if x == 0 && y == 0 {
+		return nil
+	}
+	if config.Model == ModelUC8151 {
+		return fmt.Errorf("origin offsets are not supported on %v", config.Model)
+	}
+	if x < 0 || y < 0 {
+		return fmt.Errorf("invalid origin %d,%d: must not be negative", x, y)
+	}
+	if x%8 != 0 {
+		return fmt.Errorf("invalid origin x %d: must be a multiple of 8, the RAM X address counts bytes", x)
+	}
+	spec := Models[config.Model]
+	if (width+7)/8*8+x > spec.MaxWidth || height+y > spec.MaxHeight {
+		return fmt.Errorf("origin %d,%d moves the %dx%d window past the %v RAM of %dx%d", x, y, width, height, config.Model, spec.MaxWidth, spec.MaxHeight)
+	}
+	return nil
+}
+
+func newController(model Model, d *Display) controller {
+	switch model {
+	case ModelUC8151:
+		return &uc8151{d: d}
+	default:
+		return &ssd1680{d: d}
+	}
+}
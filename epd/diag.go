@@ -0,0 +1,107 @@
+package epd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/gpio"
+)
+
+// statusReader is implemented by controllers with a readable status
+// register.
+type statusReader interface {
+	statusCommand() byte
+}
+
+// ErrProbeNotSupported is returned by ProbeResponsive when the model has no
+// readable register or the SPI connection cannot read.
+var ErrProbeNotSupported = errors.New("MISO probe not supported on this model or connection")
+
+// ProbeResponsive is a best-effort wiring diagnostic. It reads the
+// controller's status register and reports whether anything drove MISO:
+// a line that is not wired floats or is pulled to all zeros or all ones, so
+// only other values count as a response. A false result does not prove the
+// panel is dead, since many modules do not connect MISO at all.
+func (d *Display) ProbeResponsive() (bool, error) {
+	sr, ok := d.ctrl.(statusReader)
+	if !ok || d.config.DryRun {
+		return false, ErrProbeNotSupported
+	}
+	if err := d.ensureOpen(); err != nil {
+		return false, err
+	}
+	if d.conn.Duplex() != conn.Full {
+		return false, ErrProbeNotSupported
+	}
+
+	r := make([]byte, 1)
+	err := d.withRecovery(func() error {
+		if err := d.sendCommand(sr.statusCommand()); err != nil {
+			return err
+		}
+		if err := d.setPin(d.dc, gpio.High); err != nil {
+			return err
+		}
+		if err := d.setPin(d.cs, gpio.Low); err != nil {
+			return err
+		}
+		if err := d.conn.Tx([]byte{0x00}, r); err != nil {
+			d.setPin(d.cs, gpio.High)
+			return fmt.Errorf("status read failed: %w", err)
+		}
+		return d.setPin(d.cs, gpio.High)
+	})
+	if err != nil {
+		return false, err
+	}
+	return r[0] != 0x00 && r[0] != 0xFF, nil
+}
+
+// BenchmarkSPI is a diagnostic that times sending n bytes of RAM data over
+// SPI without refreshing the panel. The bytes repeat the retained frame,
+// so with one retained the RAM keeps its contents. Without one, before the
+// first draw, white is written instead, so RAM no longer matches the panel
+// until the next full draw. Compare the result
+// with LastRefreshDuration to tell transfer cost from refresh cost.
+func (d *Display) BenchmarkSPI(n int) (time.Duration, error) {
+	if n <= 0 {
+		return 0, errors.New("benchmark size must be positive")
+	}
+
+	var elapsed time.Duration
+	err := d.withRecovery(func() error {
+		buf := make([]byte, n)
+		for i := range buf {
+			if d.frame != nil {
+				buf[i] = d.frame[i%len(d.frame)]
+			} else {
+				buf[i] = 0xFF
+			}
+		}
+
+		if err := d.ensureAwake(); err != nil {
+			return err
+		}
+		if err := d.ctrl.beginWriteRAM(); err != nil {
+			return err
+		}
+		start := d.clock.Now()
+		if err := d.sendDataBulk(buf); err != nil {
+			return err
+		}
+		elapsed = d.clock.Now().Sub(start)
+		return nil
+	})
+	return elapsed, err
+}
+
+// LastRefreshDuration returns how long the most recent full or partial
+// refresh took, from the refresh command until BUSY released, or zero if
+// nothing has been refreshed yet.
+func (d *Display) LastRefreshDuration() time.Duration {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+	return d.lastRefresh
+}
@@ -0,0 +1,87 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+)
+
+// dirtyCanvas records which pixels are touched so the next Flush only
+// refreshes the changed area.
+type dirtyCanvas struct {
+	*image.Gray
+	dirty image.Rectangle
+}
+
+func (c *dirtyCanvas) Set(x, y int, col color.Color) {
+	p := image.Pt(x, y)
+	if !p.In(c.Rect) {
+		return
+	}
+	c.Gray.Set(x, y, col)
+	c.dirty = c.dirty.Union(image.Rectangle{Min: p, Max: p.Add(image.Pt(1, 1))})
+}
+
+// canvas returns the pending edit canvas, seeding it from the retained
+// frame (or white) on first use after a refresh. The caller holds opMu.
+func (d *Display) canvas() *dirtyCanvas {
+	if d.pending == nil {
+		var base *image.Gray
+		if d.frame != nil {
+			base = d.frameToImage(d.frame)
+		} else {
+			base = image.NewGray(d.RequiredBounds())
+			for i := range base.Pix {
+				base.Pix[i] = 0xFF
+			}
+		}
+		d.pending = &dirtyCanvas{Gray: base}
+	}
+	return d.pending
+}
+
+// SetPixel sets one pixel in portrait panel coordinates. Edits accumulate
+// until Flush.
+func (d *Display) SetPixel(x, y int, c color.Color) {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+	d.canvas().Set(x, y, c)
+}
+
+// DrawLine draws a one-pixel line in portrait panel coordinates. Edits
+// accumulate until Flush.
+func (d *Display) DrawLine(x0, y0, x1, y1 int, c color.Color) {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+	drawLine(d.canvas(), x0, y0, x1, y1, c)
+}
+
+// DirtyRect returns the bounding box of edits not yet flushed, before byte
+// alignment. It is empty when there is nothing to flush.
+func (d *Display) DirtyRect() image.Rectangle {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+	if d.pending == nil {
+		return image.Rectangle{}
+	}
+	return d.pending.dirty
+}
+
+// Flush partially refreshes the bounding box of all SetPixel and DrawLine
+// edits since the last flush or full draw.
+func (d *Display) Flush() error {
+	return d.withRecovery(func() error {
+		if d.pending == nil || d.pending.dirty.Empty() {
+			return nil
+		}
+
+		buf, err := d.encodeImage(d.pending.Gray)
+		if err != nil {
+			return err
+		}
+		if err := d.refreshRegionLocked(d.pending.dirty, buf); err != nil {
+			return err
+		}
+		d.pending = nil
+		return nil
+	})
+}
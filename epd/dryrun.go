@@ -0,0 +1,124 @@
+package epd
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+)
+
+type dryRunPort struct {
+	logger *log.Logger
+	dc     *dryRunPin
+}
+
+func newDryRunPort(logger *log.Logger) *dryRunPort {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &dryRunPort{logger: logger}
+}
+
+func (p *dryRunPort) pins(config DisplayConfig) (dc, cs, rst, busy gpio.PinIO) {
+	p.dc = &dryRunPin{name: config.DCPin}
+	if config.CSPin != "" {
+		cs = &dryRunPin{name: config.CSPin, level: gpio.High}
+	}
+	return p.dc,
+		cs,
+		&dryRunPin{name: config.RSTPin, level: gpio.High},
+		&dryRunPin{name: config.BUSYPin}
+}
+
+// dryRunPin is a gpio.PinIO that only remembers its last level. Unlike
+// FakePin it keeps no history, so a long dry run does not grow.
+type dryRunPin struct {
+	name string
+
+	mu    sync.Mutex
+	level gpio.Level
+}
+
+func (p *dryRunPin) String() string                         { return p.name }
+func (p *dryRunPin) Halt() error                            { return nil }
+func (p *dryRunPin) Name() string                           { return p.name }
+func (p *dryRunPin) Number() int                            { return -1 }
+func (p *dryRunPin) Function() string                       { return "" }
+func (p *dryRunPin) Pull() gpio.Pull                        { return gpio.PullNoChange }
+func (p *dryRunPin) DefaultPull() gpio.Pull                 { return gpio.Float }
+func (p *dryRunPin) In(gpio.Pull, gpio.Edge) error          { return nil }
+func (p *dryRunPin) WaitForEdge(timeout time.Duration) bool { return false }
+
+func (p *dryRunPin) Read() gpio.Level {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.level
+}
+
+func (p *dryRunPin) Out(l gpio.Level) error {
+	p.mu.Lock()
+	p.level = l
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *dryRunPin) PWM(gpio.Duty, physic.Frequency) error {
+	return errors.New("dry run pin does not support PWM")
+}
+
+func (p *dryRunPort) String() string {
+	return "dryrun"
+}
+
+func (p *dryRunPort) Close() error {
+	p.logger.Printf("epd dryrun: close")
+	return nil
+}
+
+func (p *dryRunPort) LimitSpeed(f physic.Frequency) error {
+	return nil
+}
+
+func (p *dryRunPort) Connect(f physic.Frequency, mode spi.Mode, bits int) (spi.Conn, error) {
+	p.logger.Printf("epd dryrun: connect %s mode %d, %d bits", f, mode, bits)
+	return &dryRunConn{port: p}, nil
+}
+
+type dryRunConn struct {
+	port *dryRunPort
+}
+
+func (c *dryRunConn) String() string {
+	return "dryrun"
+}
+
+func (c *dryRunConn) Duplex() conn.Duplex {
+	return conn.Half
+}
+
+func (c *dryRunConn) Tx(w, r []byte) error {
+	kind := "data"
+	if c.port.dc != nil && c.port.dc.Read() == gpio.Low {
+		kind = "command"
+	}
+	if len(w) == 1 {
+		c.port.logger.Printf("epd dryrun: %s 0x%02X", kind, w[0])
+	} else {
+		c.port.logger.Printf("epd dryrun: %s (%d bytes)", kind, len(w))
+	}
+	return nil
+}
+
+func (c *dryRunConn) TxPackets(p []spi.Packet) error {
+	for _, pkt := range p {
+		if err := c.Tx(pkt.W, pkt.R); err != nil {
+			return err
+		}
+	}
+	return nil
+}
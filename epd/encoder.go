@@ -0,0 +1,15 @@
+package epd
+
+import "image"
+
+// Encoder converts an image to a frame buffer, replacing the built-in 1-bit
+// conversion. img is already rotated to the panel's portrait orientation
+// and is width x height pixels. The result must be LineWidth()*height bytes,
+// rows top to bottom, the most significant bit leftmost, and a set bit
+// meaning white.
+//
+// When DisplayConfig.Encoder is set, DrawImage always goes through it, so
+// StreamingWrite and the column-major landscape path are not used.
+type Encoder interface {
+	Encode(img image.Image, width, height int) ([]byte, error)
+}
@@ -6,49 +6,210 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"log"
 	"periph.io/x/conn/v3/gpio"
 	"periph.io/x/conn/v3/gpio/gpioreg"
 	"periph.io/x/conn/v3/physic"
 	"periph.io/x/conn/v3/spi"
 	"periph.io/x/conn/v3/spi/spireg"
 	"periph.io/x/host/v3"
+	"sync"
 	"time"
 )
 
-const (
-	cmdSoftwareReset         byte = 0x12
-	cmdDriverOutputControl   byte = 0x01
-	cmdDataEntryMode         byte = 0x11
-	cmdSetRamXStartEndPos    byte = 0x44
-	cmdSetRamYStartEndPos    byte = 0x45
-	cmdSetRamXCounter        byte = 0x4E
-	cmdSetRamYCounter        byte = 0x4F
-	cmdBorderWaveformControl byte = 0x3C
-	cmdDisplayUpdateControl1 byte = 0x21
-	cmdDisplayUpdateControl2 byte = 0x22
-	cmdWriteRAM              byte = 0x24
-	cmdEnterDeepSleep        byte = 0x10
-
-	dataEntryX                      byte = 0x03
-	displayUpdateSequence           byte = 0x20
-	displayUpdateSequenceNormalMode byte = 0xF7
-)
-
 type DisplayConfig struct {
+	Model Model
+
+	CustomWidth  int
+	CustomHeight int
+
 	DCPin   string
 	CSPin   string
 	RSTPin  string
 	BUSYPin string
 
-	SPIFrequency physic.Frequency
-	SPIMode      spi.Mode
+	SPIFrequency   physic.Frequency
+	SPIMode        spi.Mode
+	SPIBitsPerWord int
+	SPILSBFirst    bool
 
-	ResetHoldTime  time.Duration
-	ResetDelayTime time.Duration
+	// BitOrder is the pixel order within each byte of RAM data, for clone
+	// panels that expect LSB-first pixels.
+	BitOrder BitOrder
+
+	// BulkSPIFrequency, when set, clocks the frame buffer RAM writes at a
+	// different rate than commands, by changing the speed limit of the SPI
+	// port around each write. It must not exceed the model's
+	// MaxSPIFrequency. Ports do not report their maximum clock, so only
+	// rates the port rejects outright fail, when it is opened; the Linux
+	// spidev driver instead rounds a rate its controller cannot produce
+	// down to the nearest one it can.
+	BulkSPIFrequency physic.Frequency
+
+	SoftSPI      bool
+	SCKPin       string
+	MOSIPin      string
+	SoftSPIDelay time.Duration
+
+	// OriginX and OriginY shift the RAM window for panels whose active
+	// area does not start at the controller's first column or gate
+	// (SSD1680 only). The RAM X address counts bytes, so OriginX must be a
+	// multiple of 8; the shifted window must still fit the controller RAM.
+	OriginX int
+	OriginY int
+
+	TemperatureSensor TemperatureSensor
+	GateScan          GateScan
+	Border            BorderColor
+	InvertContent     bool
+	DataEntry         DataEntry
+
+	// RedRAMOption and BWRAMOption select how each RAM bank drives the
+	// display (SSD1680 only). The defaults, RAMNormal, match the usual
+	// init sequence.
+	RedRAMOption RAMOption
+	BWRAMOption  RAMOption
+
+	// VerticalFlip reverses the row order of every frame, independent of
+	// rotation and DataEntry, for panels whose RAM fills bottom to top.
+	// Streaming and column writes are disabled while it is set. A custom
+	// Encoder still returns rows top to bottom; its output is flipped too.
+	VerticalFlip bool
+
+	// VCOM, GateVoltage and SourceVoltage override the controller's drive
+	// voltages during init; zero keeps the default. Out-of-range values are
+	// rejected because they can permanently damage the panel.
+	//
+	// SSD1680: VCOM 0x08 (-0.2V) to 0x78 (-3.0V); GateVoltage 0x00 (20V) or
+	// 0x03 (10V) to 0x17 (20V) in 0.5V steps; see SourceVoltage.
+	// UC8151: VCOM 0x01 to 0x3A (-0.15V to -3.0V in 0.05V steps); gate and
+	// source voltages are not supported.
+	VCOM          byte
+	GateVoltage   byte
+	SourceVoltage SourceVoltage
+
+	ResetPreHigh   time.Duration
+	ResetLow       time.Duration
+	ResetPostHigh  time.Duration
 	BusyPollTime   time.Duration
 	RefreshTimeout time.Duration
 
+	// Deprecated: Use ResetPreHigh and ResetPostHigh. ResetHoldTime is
+	// used for both while they are zero.
+	ResetHoldTime time.Duration
+	// Deprecated: Use ResetLow. ResetDelayTime is used for it while it is
+	// zero.
+	ResetDelayTime time.Duration
+
+	// PowerSettleTime is waited after the booster is powered on and before
+	// the refresh waveform starts, for panels whose charge pump needs time
+	// to stabilize on a weak supply. Zero keeps the single combined update
+	// sequence.
+	PowerSettleTime time.Duration
+
+	// BusyActiveLow inverts the BUSY polarity expected for the model, for
+	// clone panels wired opposite to the Waveshare convention (active high
+	// on SSD1680 boards, active low on UC8151 boards).
+	BusyActiveLow bool
+
+	// BusyPollStrategy selects how waitBusy spaces its BUSY reads. With
+	// PollBackoff the interval starts at BusyPollMin and grows by
+	// BusyPollFactor after every read, capped at BusyPollMax.
+	BusyPollStrategy PollStrategy
+	BusyPollMin      time.Duration
+	BusyPollMax      time.Duration
+	BusyPollFactor   float64
+
+	OversizeBehavior   OversizeBehavior
+	Letterbox          LetterboxColor
+	DimensionTolerance int
+	Supersample        int
+	AutoOrient         bool
+	RotationMode       RotationMode
+
+	// StreamingWrite converts and sends DrawImage frames one row at a time
+	// instead of encoding the whole frame first, to lower peak memory. A
+	// streamed frame is not retained, so after one Redraw, SaveFrame and
+	// ShowToast return ErrNoFrame, the next partial refresh is sent as a
+	// full one, PeriodicFullRefresh skips it, WakeUp clears the panel, and
+	// the UC8151 diffs the next frame against white. It is ignored with
+	// Compositing, Encoder, VerticalFlip or CaptureDir, which all need the
+	// whole frame.
+	StreamingWrite bool
+
+	// Compositing draws every DrawImage over the retained frame, leaving
+	// pixels the image leaves transparent as they were.
+	Compositing bool
+
+	// SkipWakeRefresh stops WakeUp from redrawing the retained frame, or
+	// clearing the panel, after re-initializing the controller.
+	SkipWakeRefresh bool
+
+	// AttachExisting takes over a controller another process already
+	// initialized: the bus is opened and RST released, but no reset or
+	// init sequence is sent.
+	AttachExisting bool
+
+	// AutoRecover resets and re-initializes the controller after a busy
+	// timeout and retries the operation once.
+	AutoRecover bool
+
+	// LazyInit defers opening SPI and GPIO and initializing the controller
+	// from NewWithConfig to the first operation that talks to the panel.
+	// The config is still validated up front.
+	LazyInit bool
+
+	InitSequence []InitStep
+
+	PostRefreshAction     PostRefreshAction
+	ClockFullRefreshEvery int
+
+	// PeriodicFullRefresh, when positive, re-displays the current frame
+	// with a full refresh at this interval from a background goroutine
+	// until Close, to clear ghosting that builds up even while idle.
+	PeriodicFullRefresh time.Duration
+
+	// CleanCycles is the number of black/white refresh pairs CleanDisplay
+	// runs.
+	CleanCycles int
+
+	Pipeline    []ImageStage
+	PixelMapper func(x, y int, c color.Color) bool
+	Encoder     Encoder
+
+	RedThreshold uint8
+
+	// Metrics receives refresh and error counts. Its methods run while the
+	// driver holds its panel lock, so they must not call back into the
+	// Display; doing so deadlocks.
+	Metrics Metrics
+
+	InitialFullRefreshes    int
+	InitialPartialRefreshes int
+
+	CaptureDir string
+
+	// ShowDiff is a debugging aid: before each DrawImage the changed
+	// bounding box is partially refreshed inverted, then the real frame is
+	// shown.
+	ShowDiff bool
+
+	DryRun bool
+	Logger *log.Logger
+
+	// OnBusyStateChange is called when a busy wait starts (true) and ends
+	// (false). Like Metrics it runs while the driver holds its panel lock:
+	// it may call SuppressBusyCallback, but any other Display method
+	// (setters, Config, RefreshStats, BorderColor, draws) deadlocks.
 	OnBusyStateChange func(busy bool)
+
+	// BusyHistorySize is how many BUSY pin transitions to keep for
+	// BusyHistory. Zero disables recording.
+	BusyHistorySize int
+
+	// clock replaces the real clock for a single Display, so tests can run
+	// resets, busy waits and retries without real delays.
+	clock clock
 }
 
 func DefaultConfig() DisplayConfig {
@@ -58,28 +219,97 @@ func DefaultConfig() DisplayConfig {
 		RSTPin:  "GPIO17",
 		BUSYPin: "GPIO24",
 
-		SPIFrequency: 1 * physic.MegaHertz,
-		SPIMode:      spi.Mode0,
+		SPIFrequency:   1 * physic.MegaHertz,
+		SPIMode:        spi.Mode0,
+		SPIBitsPerWord: 8,
+
+		SCKPin:  "GPIO11",
+		MOSIPin: "GPIO10",
+
+		TemperatureSensor: TemperatureSensorInternal,
 
-		ResetHoldTime:  20 * time.Millisecond,
-		ResetDelayTime: 2 * time.Millisecond,
+		ResetPreHigh:   20 * time.Millisecond,
+		ResetLow:       2 * time.Millisecond,
+		ResetPostHigh:  20 * time.Millisecond,
 		BusyPollTime:   10 * time.Millisecond,
 		RefreshTimeout: 10 * time.Second,
 
+		BusyPollMin:    1 * time.Millisecond,
+		BusyPollMax:    50 * time.Millisecond,
+		BusyPollFactor: 2,
+
+		Supersample: 1,
+
+		ClockFullRefreshEvery: 60,
+
+		CleanCycles: 3,
+
+		RedThreshold: 0x40,
+
 		OnBusyStateChange: nil,
 	}
 }
 
 type Display struct {
-	port   spi.PortCloser
-	conn   spi.Conn
-	dc     gpio.PinOut
-	cs     gpio.PinOut
-	rst    gpio.PinOut
-	busy   gpio.PinIn
-	width  int
-	height int
-	config DisplayConfig
+	port     spi.PortCloser
+	conn     spi.Conn
+	dc       gpio.PinOut
+	cs       gpio.PinOut
+	rst      gpio.PinOut
+	busy     gpio.PinIn
+	ctrl     controller
+	width    int
+	height   int
+	config   DisplayConfig
+	frame    []byte
+	border   BorderColor
+	inverted bool
+
+	pending *dirtyCanvas
+
+	// landscape records whether the retained frame was drawn from a
+	// landscape image, so SaveFrame can rotate it back. It is set with
+	// frame by full writes; partial refreshes keep it. Guarded by opMu.
+	landscape bool
+
+	inStandby bool
+	asleep    bool
+
+	busyMu                 sync.Mutex
+	busyCallbackSuppressed bool
+
+	// opMu serializes panel writes (see withRecovery) with the runtime
+	// setters, so a setter never changes state in the middle of a refresh.
+	opMu sync.Mutex
+
+	maxTxSize int
+
+	clock clock
+
+	metrics Metrics
+
+	fullRefreshes    int
+	partialRefreshes int
+	lastRefresh      time.Duration
+
+	clockTicks int
+
+	busyHistory *busyRing
+
+	periodicStop chan struct{}
+	periodicDone chan struct{}
+
+	closed bool
+
+	asyncMu         sync.Mutex
+	staged          []byte
+	stagedLandscape bool
+	asyncQueued     *asyncJob
+	asyncRunning    bool
+	asyncWG         sync.WaitGroup
+
+	batchMu sync.Mutex
+	batch   lineBatch
 }
 
 func New() (*Display, error) {
@@ -87,253 +317,661 @@ func New() (*Display, error) {
 }
 
 func NewWithConfig(config DisplayConfig) (*Display, error) {
-	if _, err := host.Init(); err != nil {
-		return nil, fmt.Errorf("host init failed: %w", err)
+	d, err := newDisplay(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.LazyInit {
+		if err := d.open(); err != nil {
+			return nil, err
+		}
+	}
+
+	d.startPeriodicRefresh()
+	return d, nil
+}
+
+// NewWithConn builds a Display on an already connected SPI conn and GPIO
+// pins instead of opening them from the config, for example to share a bus
+// or to run against RecordingConn and FakePin in tests. The pin names, SPI
+// settings and port options in config are ignored; cs may be nil when the
+// peripheral drives chip select. The controller is initialized (or
+// attached) right away, and Close leaves conn and the pins to the caller.
+func NewWithConn(config DisplayConfig, conn spi.Conn, dc, cs, rst gpio.PinOut, busy gpio.PinIn) (*Display, error) {
+	if config.BulkSPIFrequency != 0 {
+		return nil, errors.New("bulk SPI frequency needs a port and is not supported with NewWithConn")
+	}
+	d, err := newDisplay(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.attachConn(conn, dc, cs, rst, busy); err != nil {
+		return nil, err
+	}
+
+	d.startPeriodicRefresh()
+	return d, nil
+}
+
+// attachConn installs conn and the pins behind a port whose Close is a
+// no-op, then brings the controller up.
+func (d *Display) attachConn(conn spi.Conn, dc, cs, rst gpio.PinOut, busy gpio.PinIn) error {
+	if conn == nil || dc == nil || rst == nil || busy == nil {
+		return errors.New("conn and DC, RST and BUSY pins are required")
+	}
+	d.port, d.conn = externalPort{conn}, conn
+	d.dc, d.cs, d.rst, d.busy = dc, cs, rst, busy
+	d.maxTxSize = connMaxTxSize(conn)
+	return d.bringUp()
+}
+
+// newDisplay validates config and builds an unopened Display.
+func newDisplay(config DisplayConfig) (*Display, error) {
+	width, height, err := panelSize(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, err := spiWordFormat(config); err != nil {
+		return nil, err
+	}
+	if err := checkOrigin(config, width, height); err != nil {
+		return nil, err
+	}
+	if config.Model == ModelUC8151 {
+		if err := checkUC8151Config(config); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkBulkFrequency(config); err != nil {
+		return nil, err
 	}
+	if config.ResetPreHigh == 0 && config.ResetPostHigh == 0 {
+		config.ResetPreHigh = config.ResetHoldTime
+		config.ResetPostHigh = config.ResetHoldTime
+	}
+	if config.ResetLow == 0 {
+		config.ResetLow = config.ResetDelayTime
+	}
+
+	d := &Display{
+		width:    width,
+		height:   height,
+		config:   config,
+		border:   config.Border,
+		inverted: config.InvertContent,
+		clock:    clockFor(config),
 
-	port, err := spireg.Open("")
+		busyHistory: newBusyRing(config.BusyHistorySize),
+
+		fullRefreshes:    config.InitialFullRefreshes,
+		partialRefreshes: config.InitialPartialRefreshes,
+	}
+	d.ctrl = newController(config.Model, d)
+	d.metrics = config.Metrics
+	if d.metrics == nil {
+		d.metrics = noopMetrics{}
+	}
+	return d, nil
+}
+
+// open acquires the SPI port and GPIO pins and brings the controller up,
+// either by attaching to its existing state or with a full init. With
+// LazyInit it runs on the first bus operation instead of in NewWithConfig.
+// On failure everything acquired is released again, so a later call can
+// retry.
+func (d *Display) open() error {
+	config := d.config
+	bits, mode, err := spiWordFormat(config)
 	if err != nil {
-		return nil, fmt.Errorf("SPI open failed: %w", err)
+		return err
 	}
 
-	conn, err := port.Connect(config.SPIFrequency, config.SPIMode, 8)
+	var port spi.PortCloser
+	if config.DryRun {
+		port = newDryRunPort(config.Logger)
+	} else {
+		if _, err := host.Init(); err != nil {
+			return fmt.Errorf("host init failed: %w", err)
+		}
+
+		if config.SoftSPI {
+			port, err = newSoftSPIPort(config)
+		} else {
+			port, err = spireg.Open("")
+		}
+		if err != nil {
+			return fmt.Errorf("SPI open failed: %w", err)
+		}
+	}
+
+	conn, err := port.Connect(connectFrequency(config), mode, bits)
 	if err != nil {
 		if closeErr := port.Close(); closeErr != nil {
-			return nil, fmt.Errorf("SPI connect failed and port close failed: %w", closeErr)
+			return fmt.Errorf("SPI connect failed and port close failed: %w", closeErr)
 		}
-		return nil, fmt.Errorf("SPI connect failed: %w", err)
+		return fmt.Errorf("SPI connect at %s failed: %w", connectFrequency(config), err)
 	}
 
-	dc := gpioreg.ByName(config.DCPin)
-	cs := gpioreg.ByName(config.CSPin)
-	rst := gpioreg.ByName(config.RSTPin)
-	busy := gpioreg.ByName(config.BUSYPin)
+	var dc, cs, rst, busy gpio.PinIO
+	if dry, ok := port.(*dryRunPort); ok {
+		dc, cs, rst, busy = dry.pins(config)
+	} else {
+		dc = gpioreg.ByName(config.DCPin)
+		if config.CSPin != "" {
+			cs = gpioreg.ByName(config.CSPin)
+		}
+		rst = gpioreg.ByName(config.RSTPin)
+		busy = gpioreg.ByName(config.BUSYPin)
+	}
 
-	if dc == nil || cs == nil || rst == nil || busy == nil {
+	if dc == nil || (cs == nil && config.CSPin != "") || rst == nil || busy == nil {
 		if closeErr := port.Close(); closeErr != nil {
-			return nil, fmt.Errorf("GPIO init failed and port close failed: %w", closeErr)
+			return fmt.Errorf("GPIO init failed and port close failed: %w", closeErr)
 		}
-		return nil, errors.New("failed to initialize GPIO pins")
+		return errors.New("failed to initialize GPIO pins")
 	}
 
-	d := &Display{
-		port:   port,
-		conn:   conn,
-		dc:     dc,
-		cs:     cs,
-		rst:    rst,
-		busy:   busy,
-		width:  122,
-		height: 250,
-		config: config,
+	d.port, d.conn = port, conn
+	if err := d.limitCommandSpeed(); err != nil {
+		d.port, d.conn = nil, nil
+		if closeErr := port.Close(); closeErr != nil {
+			return fmt.Errorf("%w (port close failed: %v)", err, closeErr)
+		}
+		return err
+	}
+
+	d.dc, d.cs, d.rst, d.busy = dc, cs, rst, busy
+	d.maxTxSize = connMaxTxSize(conn)
+	return d.bringUp()
+}
+
+// bringUp attaches to or initializes the controller once the bus is open,
+// closing the bus again on failure.
+func (d *Display) bringUp() error {
+	if d.config.AttachExisting {
+		if err := d.setPin(d.rst, gpio.High); err != nil {
+			if closeErr := d.closeBus(false); closeErr != nil {
+				return fmt.Errorf("attach failed and close failed: %w", closeErr)
+			}
+			return fmt.Errorf("attach failed: %w", err)
+		}
+		return nil
 	}
 
-	if err := d.init(); err != nil {
-		if closeErr := d.Close(); closeErr != nil {
-			return nil, fmt.Errorf("display init failed and close failed: %w", closeErr)
+	if err := d.ctrl.init(); err != nil {
+		if closeErr := d.closeBus(true); closeErr != nil {
+			return fmt.Errorf("display init failed and close failed: %w", closeErr)
 		}
-		return nil, fmt.Errorf("display init failed: %w", err)
+		return fmt.Errorf("display init failed: %w", err)
 	}
+	return nil
+}
 
-	return d, nil
+// ErrClosed is returned by operations on a Display after Close.
+var ErrClosed = errors.New("display is closed")
+
+// ensureOpen opens the bus on first use when LazyInit deferred it.
+func (d *Display) ensureOpen() error {
+	if d.port != nil {
+		return nil
+	}
+	d.asyncMu.Lock()
+	closed := d.closed
+	d.asyncMu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+	return d.open()
+}
+
+func spiWordFormat(config DisplayConfig) (int, spi.Mode, error) {
+	bits := config.SPIBitsPerWord
+	if bits == 0 {
+		bits = 8
+	}
+	if bits != 8 {
+		return 0, 0, fmt.Errorf("unsupported SPI word size: %d bits (panel commands are single bytes, use 8)", bits)
+	}
+
+	mode := config.SPIMode
+	if config.SPILSBFirst {
+		mode |= spi.LSBFirst
+	}
+	return bits, mode, nil
+}
+
+func NewWithRetry(config DisplayConfig, attempts int, delay time.Duration) (*Display, error) {
+	if attempts < 1 {
+		return nil, fmt.Errorf("invalid retry attempts: %d", attempts)
+	}
+
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			clockFor(config).Sleep(delay)
+		}
+
+		d, err := NewWithConfig(config)
+		if err == nil {
+			return d, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("display init failed after %d attempts: %w", attempts, lastErr)
 }
 
 func (d *Display) reset() error {
 	if err := d.setPin(d.rst, gpio.High); err != nil {
 		return err
 	}
-	time.Sleep(d.config.ResetHoldTime)
+	d.clock.Sleep(d.config.ResetPreHigh)
 
 	if err := d.setPin(d.rst, gpio.Low); err != nil {
 		return err
 	}
-	time.Sleep(d.config.ResetDelayTime)
+	d.clock.Sleep(d.config.ResetLow)
 
 	if err := d.setPin(d.rst, gpio.High); err != nil {
 		return err
 	}
-	time.Sleep(d.config.ResetHoldTime)
+	d.clock.Sleep(d.config.ResetPostHigh)
 	return nil
 }
 
 func (d *Display) waitBusy() error {
-	if d.config.OnBusyStateChange != nil {
-		d.config.OnBusyStateChange(true)
-		defer d.config.OnBusyStateChange(false)
+	return d.waitBusyTimeout(d.config.RefreshTimeout)
+}
+
+func (d *Display) waitBusyTimeout(timeout time.Duration) error {
+	// The callback runs without busyMu so it may call SuppressBusyCallback
+	// or WithoutBusyCallback itself.
+	d.busyMu.Lock()
+	notify := d.config.OnBusyStateChange
+	if d.busyCallbackSuppressed {
+		notify = nil
+	}
+	d.busyMu.Unlock()
+
+	if notify != nil {
+		notify(true)
+		defer notify(false)
+	}
+
+	d.busyMu.Lock()
+	defer d.busyMu.Unlock()
+
+	if d.config.DryRun {
+		return nil
 	}
 
-	deadline := time.Now().Add(d.config.RefreshTimeout)
-	for time.Now().Before(deadline) {
-		if d.busy.Read() == gpio.Low {
+	poll := d.newPoller()
+	expired := d.clock.After(timeout)
+	for {
+		if !d.readBusy() {
 			return nil
 		}
-		time.Sleep(d.config.BusyPollTime)
+		select {
+		case <-expired:
+			d.metrics.IncTimeout()
+			return ErrBusyTimeout
+		default:
+		}
+		d.clock.Sleep(poll())
 	}
-	return errors.New("timeout waiting for display to be ready")
 }
 
+// PollStrategy controls the interval between BUSY reads while waiting for
+// the controller.
+type PollStrategy int
+
+const (
+	// PollFixed reads BUSY every BusyPollTime.
+	PollFixed PollStrategy = iota
+	// PollBackoff starts at BusyPollMin and multiplies the interval by
+	// BusyPollFactor up to BusyPollMax, so short partial refreshes return
+	// quickly while long full refreshes do not spin.
+	PollBackoff
+)
+
+// newPoller returns a function yielding successive poll intervals for one
+// busy wait.
+func (d *Display) newPoller() func() time.Duration {
+	if d.config.BusyPollStrategy != PollBackoff {
+		return func() time.Duration { return d.config.BusyPollTime }
+	}
+
+	next := d.config.BusyPollMin
+	max := d.config.BusyPollMax
+	factor := d.config.BusyPollFactor
+	if factor < 1 {
+		factor = 1
+	}
+	if next <= 0 {
+		next = responsePollTime
+	}
+	if max < next {
+		max = next
+	}
+	return func() time.Duration {
+		interval := next
+		if interval > max {
+			interval = max
+		}
+		next = time.Duration(float64(interval) * factor)
+		return interval
+	}
+}
+
+// busyActiveLevel is the BUSY level that means busy: the controller's own
+// convention, inverted when BusyActiveLow is set.
+func (d *Display) busyActiveLevel() gpio.Level {
+	level := d.ctrl.busyLevel()
+	if d.config.BusyActiveLow {
+		level = !level
+	}
+	return level
+}
+
+// Busy reports whether the controller is currently signalling busy. It is
+// false before the bus is opened and in dry runs. It checks the bus under
+// the panel lock, so while a driver operation runs it waits for it to
+// finish; use OnBusyStateChange to follow a refresh as it happens.
+func (d *Display) Busy() bool {
+	d.opMu.Lock()
+	open := d.port != nil
+	d.opMu.Unlock()
+	if !open || d.config.DryRun {
+		return false
+	}
+	return d.readBusy()
+}
+
+var ErrBusyTimeout = errors.New("timeout waiting for display to be ready")
+
+// ErrNoResponse is returned from New when the controller never signals busy
+// after a command that must make it busy, which almost always means the
+// panel is miswired or on a different SPI mode than configured.
+var ErrNoResponse = errors.New("display did not respond; check wiring/SPI mode")
+
+const (
+	responseTimeout  = 100 * time.Millisecond
+	responsePollTime = 100 * time.Microsecond
+)
+
+// expectBusy waits for the controller to assert BUSY after a command that
+// starts an internal operation. It polls much faster than waitBusy so short
+// pulses are not missed.
+func (d *Display) expectBusy() error {
+	d.busyMu.Lock()
+	defer d.busyMu.Unlock()
+
+	if d.config.DryRun {
+		return nil
+	}
+
+	expired := d.clock.After(responseTimeout)
+	for {
+		if d.readBusy() {
+			return nil
+		}
+		select {
+		case <-expired:
+			return ErrNoResponse
+		default:
+		}
+		d.clock.Sleep(responsePollTime)
+	}
+}
+
+// SuppressBusyCallback stops (true) or resumes (false) OnBusyStateChange
+// notifications, for example during a burst of partial refreshes. A busy
+// wait in progress finishes with the setting it started with, so the
+// callback still sees balanced true/false pairs.
+func (d *Display) SuppressBusyCallback(suppress bool) {
+	d.busyMu.Lock()
+	defer d.busyMu.Unlock()
+	d.busyCallbackSuppressed = suppress
+}
+
+// WithoutBusyCallback runs fn with OnBusyStateChange suppressed and restores
+// the previous setting afterwards, even if fn fails or panics.
+func (d *Display) WithoutBusyCallback(fn func() error) error {
+	d.busyMu.Lock()
+	prev := d.busyCallbackSuppressed
+	d.busyCallbackSuppressed = true
+	d.busyMu.Unlock()
+
+	defer d.SuppressBusyCallback(prev)
+	return fn()
+}
+
+// SetRefreshTimeout changes how long busy waits may take. Like every
+// runtime setter it waits for an in-flight refresh to finish and applies to
+// the next one.
+func (d *Display) SetRefreshTimeout(timeout time.Duration) {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+	d.config.RefreshTimeout = timeout
+}
+
+// sendDataBulk sends frame buffer pixel data in the configured BitOrder.
 func (d *Display) sendDataBulk(data []byte) error {
+	return d.sendRawDataBulk(d.pixelBytes(data))
+}
+
+func (d *Display) sendRawDataBulk(data []byte) error {
 	if err := d.setPin(d.dc, gpio.High); err != nil {
 		return fmt.Errorf("DC pin set failed: %w", err)
 	}
 	if err := d.setPin(d.cs, gpio.Low); err != nil {
 		return fmt.Errorf("CS pin set failed: %w", err)
 	}
-	if err := d.conn.Tx(data, nil); err != nil {
+	if err := d.txBulk(data); err != nil {
 		return fmt.Errorf("bulk data transmission failed: %w", err)
 	}
 	return d.setPin(d.cs, gpio.High)
 }
 
+// setPin drives an output pin. A nil pin is skipped, which is how an empty
+// CSPin leaves chip select to the SPI peripheral.
 func (d *Display) setPin(pin gpio.PinOut, level gpio.Level) error {
+	if pin == nil {
+		return nil
+	}
 	if err := pin.Out(level); err != nil {
 		return fmt.Errorf("failed to set pin: %w", err)
 	}
 	return nil
 }
 
-func (d *Display) init() error {
-	if err := d.reset(); err != nil {
-		return err
-	}
-	if err := d.waitBusy(); err != nil {
-		return err
-	}
-
-	if err := d.sendCommand(cmdSoftwareReset); err != nil {
-		return err
-	}
-	if err := d.waitBusy(); err != nil {
-		return err
-	}
+func (d *Display) DrawImage(img image.Image) error {
+	return d.drawPrepared(d.prepareImage(img), orientAuto)
+}
 
-	if err := d.setDriverOutputControl(); err != nil {
-		return err
+func (d *Display) drawPrepared(img image.Image, o orientation) error {
+	if d.config.Compositing {
+		return d.drawComposited(img, o)
 	}
 
-	if err := d.setDataEntryMode(dataEntryX); err != nil {
-		return err
+	if d.config.ShowDiff {
+		if err := d.showDiff(img, o); err != nil {
+			return err
+		}
 	}
 
-	if err := d.setWindow(0, 0, d.width-1, d.height-1); err != nil {
-		return err
+	if d.config.StreamingWrite && d.config.Encoder == nil && !d.config.VerticalFlip && d.config.CaptureDir == "" {
+		return d.withRecovery(func() error {
+			return d.streamImage(img, o)
+		})
 	}
 
-	if err := d.setBorderWaveform(); err != nil {
-		return err
+	bounds := img.Bounds()
+	if d.isLandscape(bounds.Dx(), bounds.Dy(), o) {
+		cw, ok, err := d.columnRotation()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return d.withRecovery(func() error {
+				return d.drawLandscapeColumns(img, cw)
+			})
+		}
 	}
 
-	if err := d.sendCommand(cmdDisplayUpdateControl1); err != nil {
-		return err
-	}
-	if err := d.sendData(0x00); err != nil {
-		return err
-	}
-	if err := d.sendData(0x80); err != nil {
+	displayBuf, landscape, err := d.encodeOriented(img, o)
+	if err != nil {
 		return err
 	}
-
-	return d.waitBusy()
+	return d.showFrame(displayBuf, landscape)
 }
 
-func (d *Display) setDriverOutputControl() error {
-	if err := d.sendCommand(cmdDriverOutputControl); err != nil {
-		return err
-	}
-	if err := d.sendData(0xf9); err != nil {
-		return err
-	}
-	if err := d.sendData(0x00); err != nil {
-		return err
-	}
-	return d.sendData(0x00)
+func (d *Display) encodeImage(img image.Image) ([]byte, error) {
+	buf, _, err := d.encodeOriented(img, orientAuto)
+	return buf, err
 }
 
-func (d *Display) setDataEntryMode(mode byte) error {
-	if err := d.sendCommand(cmdDataEntryMode); err != nil {
-		return err
+// encodeOriented is encodeImage with the orientation forced by
+// DrawPortrait or DrawLandscape. It also reports whether img was rotated
+// onto the panel, for the frame write to record.
+func (d *Display) encodeOriented(img image.Image, o orientation) ([]byte, bool, error) {
+	sourceImg, landscape, err := d.orientImage(img, o)
+	if err != nil {
+		return nil, false, err
 	}
-	return d.sendData(mode)
-}
 
-func (d *Display) setBorderWaveform() error {
-	if err := d.sendCommand(cmdBorderWaveformControl); err != nil {
-		return err
-	}
-	return d.sendData(0x05)
-}
+	var displayBuf []byte
+	if d.config.Encoder != nil {
+		displayBuf, err = d.config.Encoder.Encode(sourceImg, d.width, d.height)
+		if err != nil {
+			return nil, false, fmt.Errorf("custom encoder failed: %w", err)
+		}
+		if len(displayBuf) != d.BufferSize() {
+			return nil, false, fmt.Errorf("custom encoder returned %d bytes, want %d", len(displayBuf), d.BufferSize())
+		}
+	} else if d.config.PixelMapper != nil {
+		displayBuf = d.mapToDisplayBuffer(sourceImg)
+	} else if gray, ok := sourceImg.(*image.Gray); ok {
+		displayBuf = d.grayToDisplayBuffer(gray)
+	} else if gray16, ok := sourceImg.(*image.Gray16); ok {
+		displayBuf = d.gray16ToDisplayBuffer(gray16)
+	} else {
+		palette := []color.Color{color.Black, color.White}
+		palettedImg := image.NewPaletted(sourceImg.Bounds(), palette)
+		draw.Draw(palettedImg, palettedImg.Bounds(), sourceImg, sourceImg.Bounds().Min, draw.Src)
 
-func (d *Display) setWindow(xStart, yStart, xEnd, yEnd int) error {
-	if err := d.sendCommand(cmdSetRamXStartEndPos); err != nil {
-		return err
-	}
-	if err := d.sendData(byte((xStart >> 3) & 0xFF)); err != nil {
-		return err
-	}
-	if err := d.sendData(byte((xEnd >> 3) & 0xFF)); err != nil {
-		return err
+		displayBuf, err = d.convertToDisplayBuffer(palettedImg)
+		if err != nil {
+			return nil, false, err
+		}
 	}
 
-	if err := d.sendCommand(cmdSetRamYStartEndPos); err != nil {
-		return err
-	}
-	if err := d.sendData(byte(yStart & 0xFF)); err != nil {
-		return err
-	}
-	if err := d.sendData(byte((yStart >> 8) & 0xFF)); err != nil {
-		return err
+	d.flipVertical(displayBuf)
+	return displayBuf, landscape, nil
+}
+
+// flipVertical reverses the row order of a frame buffer in place when
+// VerticalFlip is set, for panels that fill RAM bottom to top.
+func (d *Display) flipVertical(buf []byte) {
+	if !d.config.VerticalFlip {
+		return
 	}
-	if err := d.sendData(byte(yEnd & 0xFF)); err != nil {
-		return err
+	lineWidth := d.LineWidth()
+	for top, bottom := 0, d.height-1; top < bottom; top, bottom = top+1, bottom-1 {
+		a := buf[top*lineWidth : (top+1)*lineWidth]
+		b := buf[bottom*lineWidth : (bottom+1)*lineWidth]
+		for i := range a {
+			a[i], b[i] = b[i], a[i]
+		}
 	}
-	return d.sendData(byte((yEnd >> 8) & 0xFF))
 }
 
-func (d *Display) DrawImage(img image.Image) error {
+// orientImage fits img and rotates it to portrait if it is drawn in
+// landscape, which it reports.
+func (d *Display) orientImage(img image.Image, o orientation) (image.Image, bool, error) {
+	img = d.fitOversize(img)
 	bounds := img.Bounds()
 	width := bounds.Dx()
 	height := bounds.Dy()
 
-	var sourceImg image.Image
-	if width == d.height && height == d.width {
+	if d.isLandscape(width, height, o) {
 		rotated := image.NewRGBA(image.Rect(0, 0, height, width))
 		for y := 0; y < height; y++ {
 			for x := 0; x < width; x++ {
-				rotated.Set(y, width-x-1, img.At(x, y))
+				rotated.Set(y, width-x-1, img.At(bounds.Min.X+x, bounds.Min.Y+y))
 			}
 		}
-		sourceImg = rotated
+		return rotated, true, nil
 	} else if width == d.width && height == d.height {
-		sourceImg = img
-	} else {
-		return fmt.Errorf("invalid image dimensions: must be %dx%d or %dx%d",
-			d.width, d.height, d.height, d.width)
+		return img, false, nil
+	}
+	return nil, false, d.dimensionError(width, height)
+}
+
+func (d *Display) DrawImageCentered(img image.Image, bg color.Color) error {
+	bounds := img.Bounds()
+	if bounds.Dx() > d.width || bounds.Dy() > d.height {
+		return fmt.Errorf("image %dx%d exceeds panel size %dx%d",
+			bounds.Dx(), bounds.Dy(), d.width, d.height)
 	}
 
-	palette := []color.Color{color.Black, color.White}
-	palettedImg := image.NewPaletted(sourceImg.Bounds(), palette)
-	draw.Draw(palettedImg, palettedImg.Bounds(), sourceImg, image.Point{}, draw.Src)
+	canvas := image.NewRGBA(image.Rect(0, 0, d.width, d.height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
 
-	displayBuf, err := d.convertToDisplayBuffer(palettedImg)
-	if err != nil {
+	offset := image.Pt((d.width-bounds.Dx())/2, (d.height-bounds.Dy())/2)
+	dst := image.Rectangle{Min: offset, Max: offset.Add(bounds.Size())}
+	draw.Draw(canvas, dst, img, bounds.Min, draw.Over)
+
+	return d.DrawImage(canvas)
+}
+
+func (d *Display) writeRAM(buf []byte) error {
+	if err := d.captureFrame(buf); err != nil {
 		return err
 	}
+	if err := d.ensureAwake(); err != nil {
+		return err
+	}
+	if err := d.ctrl.beginWriteRAM(); err != nil {
+		return err
+	}
+	return d.sendDataBulk(buf)
+}
 
-	if err := d.sendCommand(cmdWriteRAM); err != nil {
+func (d *Display) update() error {
+	if err := d.ensureAwake(); err != nil {
 		return err
 	}
-	if err := d.sendDataBulk(displayBuf); err != nil {
+
+	start := d.clock.Now()
+	if err := d.ctrl.update(); err != nil {
 		return err
 	}
+	d.lastRefresh = d.clock.Now().Sub(start)
+	d.metrics.IncRefresh()
+	d.metrics.ObserveRefreshDuration(d.lastRefresh)
+	d.fullRefreshes++
+	return d.afterRefresh()
+}
 
-	return d.update()
+// afterRefresh performs the configured PostRefreshAction.
+func (d *Display) afterRefresh() error {
+	switch d.config.PostRefreshAction {
+	case PostRefreshStandby:
+		return d.standby()
+	case PostRefreshDeepSleep:
+		return d.sleep()
+	default:
+		return nil
+	}
 }
 
 func (d *Display) convertToDisplayBuffer(img *image.Paletted) ([]byte, error) {
 	bounds := img.Bounds()
 	width := bounds.Dx()
 	height := bounds.Dy()
-	lineWidth := (d.width + 7) / 8
+	lineWidth := d.LineWidth()
 	buf := make([]byte, lineWidth*d.height)
 
 	for y := 0; y < height; y++ {
@@ -342,7 +980,7 @@ func (d *Display) convertToDisplayBuffer(img *image.Paletted) ([]byte, error) {
 				continue
 			}
 
-			colorIdx := img.ColorIndexAt(x, y)
+			colorIdx := img.ColorIndexAt(bounds.Min.X+x, bounds.Min.Y+y)
 			if colorIdx == 1 {
 				byteIdx := x/8 + y*lineWidth
 				bitIdx := uint(7 - x%8)
@@ -354,17 +992,53 @@ func (d *Display) convertToDisplayBuffer(img *image.Paletted) ([]byte, error) {
 	return buf, nil
 }
 
-func (d *Display) update() error {
-	if err := d.sendCommand(cmdDisplayUpdateControl2); err != nil {
-		return err
+func (d *Display) mapToDisplayBuffer(img image.Image) []byte {
+	bounds := img.Bounds()
+	lineWidth := d.LineWidth()
+	buf := make([]byte, lineWidth*d.height)
+
+	for y := 0; y < d.height && y < bounds.Dy(); y++ {
+		for x := 0; x < d.width && x < bounds.Dx(); x++ {
+			if !d.config.PixelMapper(x, y, img.At(bounds.Min.X+x, bounds.Min.Y+y)) {
+				buf[x/8+y*lineWidth] |= 1 << uint(7-x%8)
+			}
+		}
 	}
-	if err := d.sendData(displayUpdateSequenceNormalMode); err != nil {
-		return err
+
+	return buf
+}
+
+func (d *Display) grayToDisplayBuffer(img *image.Gray) []byte {
+	bounds := img.Bounds()
+	lineWidth := d.LineWidth()
+	buf := make([]byte, lineWidth*d.height)
+
+	for y := 0; y < d.height && y < bounds.Dy(); y++ {
+		row := img.Pix[img.PixOffset(bounds.Min.X, bounds.Min.Y+y):]
+		for x := 0; x < d.width && x < bounds.Dx(); x++ {
+			if row[x] >= 0x80 {
+				buf[x/8+y*lineWidth] |= 1 << uint(7-x%8)
+			}
+		}
 	}
-	if err := d.sendCommand(displayUpdateSequence); err != nil {
-		return err
+
+	return buf
+}
+
+func (d *Display) gray16ToDisplayBuffer(img *image.Gray16) []byte {
+	bounds := img.Bounds()
+	lineWidth := d.LineWidth()
+	buf := make([]byte, lineWidth*d.height)
+
+	for y := 0; y < d.height && y < bounds.Dy(); y++ {
+		for x := 0; x < d.width && x < bounds.Dx(); x++ {
+			if img.Gray16At(bounds.Min.X+x, bounds.Min.Y+y).Y >= 0x8000 {
+				buf[x/8+y*lineWidth] |= 1 << uint(7-x%8)
+			}
+		}
 	}
-	return d.waitBusy()
+
+	return buf
 }
 
 func (d *Display) Clear(white bool) error {
@@ -373,38 +1047,408 @@ func (d *Display) Clear(white bool) error {
 		targetColor = 0xFF
 	}
 
-	lineWidth := (d.width + 7) / 8
+	lineWidth := d.LineWidth()
 	buf := make([]byte, lineWidth*d.height)
 	for i := range buf {
 		buf[i] = targetColor
 	}
+	return d.clearTo(buf)
+}
 
-	if err := d.sendCommand(cmdWriteRAM); err != nil {
-		return err
+// clearTo shows buf with a full refresh like Clear, writing it to the old
+// bank as well so later partial refreshes diff against it.
+func (d *Display) clearTo(buf []byte) error {
+	return d.withRecovery(func() error {
+		// Blank the old bank too, otherwise the next partial refresh diffs
+		// against whatever the last partial session left there. On
+		// tri-color panels that bank is the red plane, which is cleared.
+		caps := d.Capabilities()
+		if ob, ok := d.ctrl.(oldBankWriter); ok && (caps.PartialRefresh || caps.TriColor) {
+			old := buf
+			if caps.TriColor {
+				old = make([]byte, len(buf))
+			}
+			if err := d.ensureAwake(); err != nil {
+				return err
+			}
+			if err := ob.beginWriteOldRAM(); err != nil {
+				return err
+			}
+			if err := d.sendDataBulk(old); err != nil {
+				return fmt.Errorf("%w: %w", ErrFrameNotDisplayed, err)
+			}
+		}
+		return d.writeFrame(buf, d.landscape)
+	})
+}
+
+// CleanDisplay runs the deghosting cycle: CleanCycles pairs of full black
+// and full white refreshes, which reset the particle state that a single
+// full refresh leaves behind after heavy partial refresh use. The panel is
+// left white.
+func (d *Display) CleanDisplay() error {
+	cycles := d.config.CleanCycles
+	if cycles < 1 {
+		cycles = 1
 	}
-	if err := d.sendDataBulk(buf); err != nil {
-		return err
+	for i := 0; i < cycles; i++ {
+		if err := d.Clear(false); err != nil {
+			return fmt.Errorf("clean cycle %d black: %w", i+1, err)
+		}
+		if err := d.Clear(true); err != nil {
+			return fmt.Errorf("clean cycle %d white: %w", i+1, err)
+		}
 	}
+	return nil
+}
+
+var ErrNoFrame = errors.New("no frame has been drawn yet")
+
+// ErrFrameNotDisplayed wraps errors from a RAM write that failed part way.
+// No refresh is triggered afterwards, so the panel keeps showing the
+// previous image.
+var ErrFrameNotDisplayed = errors.New("frame was not displayed")
+
+// abortWrite handles a failed RAM write: it makes a best-effort attempt to
+// rewrite the last good frame so the half-written data can never be shown
+// by a later refresh, and reports that the new frame was not displayed.
+func (d *Display) abortWrite(err error) error {
+	if d.frame != nil {
+		restoreErr := d.ctrl.beginWriteRAM()
+		if restoreErr == nil {
+			restoreErr = d.sendDataBulk(d.frame)
+		}
+		if restoreErr != nil {
+			return fmt.Errorf("%w: %w (restoring previous frame failed: %v)", ErrFrameNotDisplayed, err, restoreErr)
+		}
+	}
+	return fmt.Errorf("%w: %w", ErrFrameNotDisplayed, err)
+}
+
+func (d *Display) Redraw() error {
+	return d.withRecovery(func() error {
+		if d.frame == nil {
+			return ErrNoFrame
+		}
+		return d.writeFrame(d.frame, d.landscape)
+	})
+}
+
+// showBuffer shows buf with a full refresh, keeping the recorded
+// orientation of the retained frame, for frames drawn over it in panel
+// coordinates.
+func (d *Display) showBuffer(buf []byte) error {
+	return d.withRecovery(func() error {
+		return d.writeFrame(buf, d.landscape)
+	})
+}
+
+// showFrame is showBuffer for a frame encoded from a whole image, recording
+// whether that image was drawn in landscape.
+func (d *Display) showFrame(buf []byte, landscape bool) error {
+	return d.withRecovery(func() error {
+		return d.writeFrame(buf, landscape)
+	})
+}
+
+// writeFrame writes buf to RAM, retains it with its orientation and runs a
+// full refresh. It runs under withRecovery.
+func (d *Display) writeFrame(buf []byte, landscape bool) error {
+	if err := d.writeRAM(buf); err != nil {
+		return d.abortWrite(err)
+	}
+	d.frame = buf
+	d.landscape = landscape
+	d.pending = nil
 
 	return d.update()
 }
 
+// retainedCopy returns a copy of the retained frame, or a white frame if
+// nothing has been drawn yet, for drawing over. It runs under withRecovery
+// so the copy cannot go stale before it is written.
+func (d *Display) retainedCopy() []byte {
+	buf := make([]byte, d.BufferSize())
+	if d.frame != nil {
+		copy(buf, d.frame)
+	} else {
+		for i := range buf {
+			buf[i] = 0xFF
+		}
+	}
+	return buf
+}
+
 func (d *Display) Sleep() error {
-	if err := d.sendCommand(cmdEnterDeepSleep); err != nil {
+	return d.withRecovery(d.sleep)
+}
+
+func (d *Display) sleep() error {
+	if d.port == nil {
+		return nil
+	}
+	if err := d.ctrl.sleep(); err != nil {
+		return err
+	}
+	d.asleep = true
+	return nil
+}
+
+func (d *Display) WakeUp() error {
+	err := d.withRecovery(func() error {
+		if d.port == nil {
+			if err := d.ensureOpen(); err != nil {
+				return err
+			}
+		} else if err := d.ctrl.init(); err != nil {
+			return fmt.Errorf("wake up init failed: %w", err)
+		}
+		d.inStandby = false
+		d.asleep = false
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if d.config.SkipWakeRefresh {
+		return nil
+	}
+	if err := d.Redraw(); !errors.Is(err, ErrNoFrame) {
+		return err
+	}
+	return d.Clear(true)
+}
+
+// PostRefreshAction selects what the driver does with the controller once
+// a refresh has completed.
+type PostRefreshAction int
+
+const (
+	// PostRefreshIdle leaves the controller powered, ready for the next
+	// refresh.
+	PostRefreshIdle PostRefreshAction = iota
+	// PostRefreshStandby calls Standby after every refresh.
+	PostRefreshStandby
+	// PostRefreshDeepSleep calls Sleep after every refresh; the next
+	// operation re-initializes the controller.
+	PostRefreshDeepSleep
+)
+
+// Standby powers down the booster and analog circuitry while keeping RAM
+// contents and controller state. Unlike Sleep, no reset or re-init is needed
+// afterwards: the next refresh powers the analog circuitry back on, at the
+// cost of a slightly higher idle current than deep sleep.
+func (d *Display) Standby() error {
+	return d.withRecovery(d.standby)
+}
+
+func (d *Display) standby() error {
+	if d.port == nil {
+		return nil
+	}
+	if err := d.ctrl.standby(); err != nil {
+		return err
+	}
+	d.inStandby = true
+	return nil
+}
+
+// ensureAwake brings the controller out of deep sleep (by re-initializing
+// it) or standby before RAM writes and refreshes.
+func (d *Display) ensureAwake() error {
+	if d.port == nil {
+		return d.ensureOpen()
+	}
+	if d.asleep {
+		if err := d.ctrl.init(); err != nil {
+			return fmt.Errorf("wake from deep sleep failed: %w", err)
+		}
+		d.asleep = false
+		d.inStandby = false
+		return nil
+	}
+	return d.wakeFromStandby()
+}
+
+func (d *Display) wakeFromStandby() error {
+	if !d.inStandby {
+		return nil
+	}
+	if err := d.ctrl.powerOn(); err != nil {
 		return err
 	}
-	return d.sendData(0x01)
+	d.inStandby = false
+	return nil
+}
+
+// SetBorderColor changes the color driven on the panel border. It takes
+// effect on the next refresh and is kept across WakeUp.
+func (d *Display) SetBorderColor(border BorderColor) error {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+
+	if d.port == nil {
+		d.border = border
+	} else if err := d.ctrl.setBorder(border); err != nil {
+		return err
+	}
+	d.config.Border = border
+	return nil
+}
+
+// BorderColor returns the border color last written to the controller.
+func (d *Display) BorderColor() BorderColor {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+	return d.border
+}
+
+// SetInvertContent makes the controller show the frame RAM inverted, so
+// white pixels are driven black and vice versa. The border is controlled
+// separately by SetBorderColor and is not affected. It takes effect on the
+// next refresh and is kept across WakeUp.
+func (d *Display) SetInvertContent(invert bool) error {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+
+	if d.port == nil {
+		d.inverted = invert
+	} else if err := d.ctrl.setInvert(invert); err != nil {
+		return err
+	}
+	d.config.InvertContent = invert
+	return nil
+}
+
+// ramOptionSetter is implemented by controllers with per-bank display
+// source options.
+type ramOptionSetter interface {
+	setRAMOptions(red, bw RAMOption) error
+}
+
+// SetRAMOptions changes how the red and black/white RAM banks drive the
+// display, for example to bypass a bank or invert a plane at the
+// controller. It takes effect on the next refresh and is kept across
+// WakeUp. Only the SSD1680 supports it.
+func (d *Display) SetRAMOptions(red, bw RAMOption) error {
+	rs, ok := d.ctrl.(ramOptionSetter)
+	if !ok {
+		return fmt.Errorf("model %v does not support RAM options", d.config.Model)
+	}
+
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+
+	if d.port != nil {
+		if err := rs.setRAMOptions(red, bw); err != nil {
+			return err
+		}
+	}
+	d.config.RedRAMOption = red
+	d.config.BWRAMOption = bw
+	return nil
+}
+
+// ContentInverted reports whether content inversion was last enabled.
+func (d *Display) ContentInverted() bool {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+	return d.inverted
+}
+
+// Config returns a copy of the effective configuration, including changes
+// made through the runtime setters. Slices are copied too, so modifying the
+// result never affects the Display.
+func (d *Display) Config() DisplayConfig {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+
+	config := d.config
+	config.Pipeline = append([]ImageStage(nil), d.config.Pipeline...)
+	if d.config.InitSequence != nil {
+		config.InitSequence = make([]InitStep, len(d.config.InitSequence))
+		for i, step := range d.config.InitSequence {
+			step.Data = append([]byte(nil), step.Data...)
+			config.InitSequence[i] = step
+		}
+	}
+	return config
+}
+
+func (d *Display) RequiredBounds() image.Rectangle {
+	return image.Rect(0, 0, d.width, d.height)
+}
+
+func (d *Display) dimensionError(width, height int) error {
+	return fmt.Errorf("invalid image dimensions %dx%d: must be %dx%d or %dx%d (use RequiredBounds)",
+		width, height, d.width, d.height, d.height, d.width)
 }
 
 func (d *Display) Size() (int, int) {
 	return d.width, d.height
 }
 
+// LineWidth returns the number of bytes per row in a frame buffer.
+func (d *Display) LineWidth() int {
+	return (d.width + 7) / 8
+}
+
+// BufferSize returns the length of a full frame buffer as accepted by
+// DrawBuffer.
+func (d *Display) BufferSize() int {
+	return d.LineWidth() * d.height
+}
+
 func (d *Display) Close() error {
-	if err := d.Sleep(); err != nil {
-		return err
+	d.stopPeriodicRefresh()
+	d.markClosed()
+	d.stopAsync()
+
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+	return d.closeBus(true)
+}
+
+func (d *Display) CloseWithoutSleep() error {
+	d.stopPeriodicRefresh()
+	d.markClosed()
+	d.stopAsync()
+
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+	return d.closeBus(false)
+}
+
+func (d *Display) markClosed() {
+	d.asyncMu.Lock()
+	d.closed = true
+	d.asyncMu.Unlock()
+}
+
+// closeBus releases the SPI port, putting the panel to sleep first when
+// sleep is set. It does nothing if the bus was never opened.
+func (d *Display) closeBus(sleep bool) error {
+	if d.port == nil {
+		return nil
+	}
+
+	var sleepErr error
+	if sleep && !d.asleep {
+		sleepErr = d.sleep()
+	}
+	err := d.port.Close()
+	d.port = nil
+	if err != nil {
+		if sleepErr != nil {
+			return fmt.Errorf("sleep failed (%v) and port close failed: %w", sleepErr, err)
+		}
+		return fmt.Errorf("port close failed: %w", err)
 	}
-	return d.port.Close()
+	if sleepErr != nil {
+		return fmt.Errorf("sleep failed: %w", sleepErr)
+	}
+	return nil
 }
 
 func (d *Display) sendCommand(cmd byte) error {
@@ -414,7 +1458,7 @@ func (d *Display) sendCommand(cmd byte) error {
 	if err := d.setPin(d.cs, gpio.Low); err != nil {
 		return err
 	}
-	if err := d.conn.Tx([]byte{cmd}, nil); err != nil {
+	if err := d.tx([]byte{cmd}); err != nil {
 		return err
 	}
 	return d.setPin(d.cs, gpio.High)
@@ -427,8 +1471,35 @@ func (d *Display) sendData(data byte) error {
 	if err := d.setPin(d.cs, gpio.Low); err != nil {
 		return err
 	}
-	if err := d.conn.Tx([]byte{data}, nil); err != nil {
+	if err := d.tx([]byte{data}); err != nil {
 		return err
 	}
 	return d.setPin(d.cs, gpio.High)
 }
+
+func (d *Display) tx(w []byte) error {
+	return d.txOn(d.conn, d.maxTxSize, w)
+}
+
+func (d *Display) txOn(conn spi.Conn, maxTxSize int, w []byte) error {
+	chunk := len(w)
+	if maxTxSize > 0 && maxTxSize < chunk {
+		chunk = maxTxSize
+	}
+
+	for len(w) > 0 {
+		n := chunk
+		if n > len(w) {
+			n = len(w)
+		}
+		if err := conn.Tx(w[:n], nil); err != nil {
+			d.metrics.IncSPIError()
+			if maxTxSize > 0 {
+				return fmt.Errorf("SPI transfer of %d bytes failed (driver limit %d bytes): %w", n, maxTxSize, err)
+			}
+			return err
+		}
+		w = w[n:]
+	}
+	return nil
+}
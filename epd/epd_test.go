@@ -0,0 +1,244 @@
+package epd
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+// newFakeDisplay builds a Display on fake pins and a fake clock. BUSY starts
+// at busy and only changes when the test drives it.
+func newFakeDisplay(t *testing.T, config DisplayConfig, busy gpio.Level) (*Display, *FakePin, *fakeClock, error) {
+	t.Helper()
+	clk := newFakeClock()
+	config.clock = clk
+	dc := NewFakePin("DC", gpio.High)
+	rst := NewFakePin("RST", gpio.High)
+	busyPin := NewFakePin("BUSY", busy)
+	d, err := NewWithConn(config, NewRecordingConn(dc), dc, nil, rst, busyPin)
+	return d, busyPin, clk, err
+}
+
+func TestWaitBusyTimeout(t *testing.T) {
+	t.Parallel()
+	config := DefaultConfig()
+	config.AttachExisting = true
+	config.RefreshTimeout = 5 * time.Second
+	var events []bool
+	config.OnBusyStateChange = func(busy bool) { events = append(events, busy) }
+
+	d, _, clk, err := newFakeDisplay(t, config, gpio.High)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := clk.Now()
+	if err := d.waitBusy(); !errors.Is(err, ErrBusyTimeout) {
+		t.Fatalf("waitBusy() = %v, want ErrBusyTimeout", err)
+	}
+	if elapsed := clk.Now().Sub(start); elapsed < config.RefreshTimeout || elapsed > config.RefreshTimeout+time.Second {
+		t.Errorf("timed out after %v, want about %v", elapsed, config.RefreshTimeout)
+	}
+	if len(events) != 2 || !events[0] || events[1] {
+		t.Errorf("OnBusyStateChange calls = %v, want [true false]", events)
+	}
+}
+
+func TestWaitBusyReleases(t *testing.T) {
+	t.Parallel()
+	config := DefaultConfig()
+	config.AttachExisting = true
+
+	d, busy, clk, err := newFakeDisplay(t, config, gpio.Low)
+	if err != nil {
+		t.Fatal(err)
+	}
+	busy.Pulse(gpio.High)
+	start := clk.Now()
+	if err := d.waitBusy(); err != nil {
+		t.Fatalf("waitBusy() = %v, want nil", err)
+	}
+	if elapsed := clk.Now().Sub(start); elapsed <= 0 || elapsed >= config.RefreshTimeout {
+		t.Errorf("waited %v, want one poll interval", elapsed)
+	}
+}
+
+func TestExpectBusyNoResponse(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		model Model
+		idle  gpio.Level
+	}{
+		{ModelSSD1680, gpio.Low},
+		{ModelUC8151, gpio.High},
+	} {
+		config := DefaultConfig()
+		config.Model = tc.model
+		_, _, clk, err := newFakeDisplay(t, config, tc.idle)
+		if !errors.Is(err, ErrNoResponse) {
+			t.Errorf("%v: NewWithConn() = %v, want ErrNoResponse", tc.model, err)
+			continue
+		}
+		if clk.Now().Sub(time.Unix(0, 0)) < responseTimeout {
+			t.Errorf("%v: gave up after %v, want at least %v", tc.model, clk.Now().Sub(time.Unix(0, 0)), responseTimeout)
+		}
+	}
+}
+
+func TestDeprecatedResetTimes(t *testing.T) {
+	t.Parallel()
+	config := DefaultConfig()
+	config.AttachExisting = true
+	config.ResetPreHigh, config.ResetLow, config.ResetPostHigh = 0, 0, 0
+	config.ResetHoldTime = 30 * time.Millisecond
+	config.ResetDelayTime = 5 * time.Millisecond
+
+	d, _, clk, err := newFakeDisplay(t, config, gpio.Low)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.CloseWithoutSleep()
+	start := clk.Now()
+	if err := d.reset(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := clk.Now().Sub(start); elapsed != 65*time.Millisecond {
+		t.Errorf("reset took %v, want 30ms+5ms+30ms", elapsed)
+	}
+}
+
+func TestNewWithRetry(t *testing.T) {
+	t.Parallel()
+	clk := newFakeClock()
+	config := DefaultConfig()
+	config.clock = clk
+	config.CustomWidth, config.CustomHeight = 9999, 9999
+
+	_, err := NewWithRetry(config, 3, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Fatalf("NewWithRetry() = %v, want failure after 3 attempts", err)
+	}
+	if waited := clk.Now().Sub(time.Unix(0, 0)); waited != 2*time.Second {
+		t.Errorf("waited %v between attempts, want 2s", waited)
+	}
+
+	if _, err := NewWithRetry(config, 0, time.Second); err == nil {
+		t.Error("NewWithRetry with 0 attempts succeeded")
+	}
+
+	config = DefaultConfig()
+	config.clock = clk
+	config.DryRun = true
+	config.Logger = discardLogger()
+	start := clk.Now()
+	d, err := NewWithRetry(config, 3, time.Second)
+	if err != nil {
+		t.Fatalf("NewWithRetry() = %v", err)
+	}
+	defer d.Close()
+	if waited := clk.Now().Sub(start); waited >= time.Second {
+		t.Errorf("first attempt succeeded but waited %v, including a retry delay", waited)
+	}
+}
+
+// newDryRunDisplay builds a dry-run Display that logs nowhere.
+func newDryRunDisplay(t *testing.T, config DisplayConfig) *Display {
+	t.Helper()
+	config.DryRun = true
+	config.Logger = discardLogger()
+	d, err := NewWithConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+// wantBit reports whether pixel (x, y) is white in a frame buffer.
+func wantBit(d *Display, buf []byte, x, y int) bool {
+	return buf[y*d.LineWidth()+x/8]&(1<<uint(7-x%8)) != 0
+}
+
+func TestGrayToDisplayBuffer(t *testing.T) {
+	d := newDryRunDisplay(t, DefaultConfig())
+	w, h := d.Size()
+
+	gray := image.NewGray(image.Rect(0, 0, w, h))
+	gray16 := image.NewGray16(gray.Bounds())
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// Straddle the 50% threshold: 0x7F is black, 0x80 white.
+			v := uint8(0x7F + (x+y)%2)
+			gray.SetGray(x, y, color.Gray{Y: v})
+			gray16.SetGray16(x, y, color.Gray16{Y: uint16(v) << 8})
+		}
+	}
+
+	for name, img := range map[string]image.Image{"Gray": gray, "Gray16": gray16} {
+		buf, err := d.encodeImage(img)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				if got, want := wantBit(d, buf, x, y), (x+y)%2 == 1; got != want {
+					t.Fatalf("%s: pixel (%d,%d) white = %v, want %v", name, x, y, got, want)
+				}
+			}
+		}
+		// The padding bits past the last column stay zero.
+		if pad := buf[d.LineWidth()-1] & (1<<uint(8*d.LineWidth()-w) - 1); pad != 0 {
+			t.Errorf("%s: padding bits = %08b, want 0", name, pad)
+		}
+	}
+}
+
+func TestEncodeSubImage(t *testing.T) {
+	d := newDryRunDisplay(t, DefaultConfig())
+	w, h := d.Size()
+	origin := image.Pt(30, 40)
+
+	for _, size := range []image.Point{{w, h}, {h, w}} {
+		// A pattern inside a larger canvas whose surroundings are black, so
+		// reading from the wrong origin shows up as black pixels.
+		big := image.NewRGBA(image.Rect(0, 0, origin.X+size.X+20, origin.Y+size.Y+20))
+		draw.Draw(big, big.Bounds(), image.Black, image.Point{}, draw.Src)
+		want := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				c := color.Color(color.White)
+				if x%7 == 0 && y%5 == 0 {
+					c = color.Black
+				}
+				want.Set(x, y, c)
+				big.Set(origin.X+x, origin.Y+y, c)
+			}
+		}
+		window := image.Rectangle{Min: origin, Max: origin.Add(size)}
+		gray := image.NewGray(big.Bounds())
+		draw.Draw(gray, gray.Bounds(), big, image.Point{}, draw.Src)
+
+		wantBuf, err := d.encodeImage(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, sub := range map[string]image.Image{
+			"RGBA": big.SubImage(window),
+			"Gray": gray.SubImage(window),
+		} {
+			got, err := d.encodeImage(sub)
+			if err != nil {
+				t.Fatalf("%s %v: %v", name, size, err)
+			}
+			if !bytes.Equal(got, wantBuf) {
+				t.Errorf("%s %v: sub-image encodes differently from the same pixels at origin 0,0", name, size)
+			}
+		}
+	}
+}
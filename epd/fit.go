@@ -0,0 +1,169 @@
+package epd
+
+import (
+	"image"
+	"image/draw"
+)
+
+type OversizeBehavior int
+
+const (
+	OversizeError OversizeBehavior = iota
+	OversizeCropTopLeft
+	OversizeCropCenter
+	OversizeScale
+)
+
+// LetterboxColor is the fill around an image that does not cover the whole
+// panel after fitting: the bars left by OversizeScale and OversizeCropCenter,
+// and the padding AutoOrient adds around undersized images.
+type LetterboxColor int
+
+const (
+	LetterboxWhite LetterboxColor = iota
+	LetterboxBlack
+)
+
+// letterbox returns the fill for the configured LetterboxColor.
+func (d *Display) letterbox() image.Image {
+	if d.config.Letterbox == LetterboxBlack {
+		return image.Black
+	}
+	return image.White
+}
+
+func (d *Display) targetSize(width, height int) (int, int) {
+	if d.config.AutoOrient {
+		portrait := d.usedArea(width, height, d.width, d.height)
+		landscape := d.usedArea(width, height, d.height, d.width)
+		if portrait != landscape {
+			if landscape > portrait {
+				return d.height, d.width
+			}
+			return d.width, d.height
+		}
+	}
+	if (width > height) != (d.width > d.height) {
+		return d.height, d.width
+	}
+	return d.width, d.height
+}
+
+// usedArea returns how many panel pixels a width x height image covers when
+// fitted into tw x th with the configured OversizeBehavior.
+func (d *Display) usedArea(width, height, tw, th int) int {
+	if d.config.OversizeBehavior == OversizeScale && (width > tw || height > th) {
+		if width*th > height*tw {
+			return tw * (height * tw / width)
+		}
+		return (width * th / height) * th
+	}
+	return min(width, tw) * min(height, th)
+}
+
+func (d *Display) fitOversize(img image.Image) image.Image {
+	img = d.fitTolerance(img)
+	if d.config.OversizeBehavior == OversizeError {
+		bounds := img.Bounds()
+		tw, th := d.targetSize(bounds.Dx(), bounds.Dy())
+		if bounds.Dx() <= tw && bounds.Dy() <= th {
+			return d.padUndersize(img, tw, th)
+		}
+		return img
+	}
+
+	bounds := img.Bounds()
+	tw, th := d.targetSize(bounds.Dx(), bounds.Dy())
+	if bounds.Dx() <= tw && bounds.Dy() <= th {
+		return d.padUndersize(img, tw, th)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	draw.Draw(dst, dst.Bounds(), d.letterbox(), image.Point{}, draw.Src)
+
+	switch d.config.OversizeBehavior {
+	case OversizeCropTopLeft:
+		draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Over)
+	case OversizeCropCenter:
+		offset := image.Pt((tw-bounds.Dx())/2, (th-bounds.Dy())/2)
+		draw.Draw(dst, bounds.Sub(bounds.Min).Add(offset), img, bounds.Min, draw.Over)
+	case OversizeScale:
+		scaleInto(dst, dst.Bounds(), img)
+	}
+	return dst
+}
+
+// padUndersize centers an image smaller than tw x th on a letterbox canvas
+// of that size when AutoOrient is set. Otherwise, or if the image already fits
+// exactly, it is returned unchanged.
+func (d *Display) padUndersize(img image.Image, tw, th int) image.Image {
+	bounds := img.Bounds()
+	if !d.config.AutoOrient || (bounds.Dx() == tw && bounds.Dy() == th) {
+		return img
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	draw.Draw(dst, dst.Bounds(), d.letterbox(), image.Point{}, draw.Src)
+	offset := image.Pt((tw-bounds.Dx())/2, (th-bounds.Dy())/2)
+	draw.Draw(dst, bounds.Sub(bounds.Min).Add(offset), img, bounds.Min, draw.Over)
+	return dst
+}
+
+// fitTolerance crops or pads an image that is within DimensionTolerance
+// pixels of the panel size in either orientation to the exact size. Extra
+// rows and columns on the right and bottom are dropped; missing ones are
+// filled with white.
+func (d *Display) fitTolerance(img image.Image) image.Image {
+	tol := d.config.DimensionTolerance
+	if tol <= 0 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	tw, th := d.targetSize(bounds.Dx(), bounds.Dy())
+	if bounds.Dx() == tw && bounds.Dy() == th {
+		return img
+	}
+	if abs(bounds.Dx()-tw) > tol || abs(bounds.Dy()-th) > tol {
+		return img
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+	return dst
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func scaleInto(dst draw.Image, area image.Rectangle, src image.Image) {
+	sb := src.Bounds()
+	if sb.Empty() || area.Empty() {
+		return
+	}
+
+	w, h := area.Dx(), sb.Dy()*area.Dx()/sb.Dx()
+	if h > area.Dy() {
+		w, h = sb.Dx()*area.Dy()/sb.Dy(), area.Dy()
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	x0 := area.Min.X + (area.Dx()-w)/2
+	y0 := area.Min.Y + (area.Dy()-h)/2
+	for y := 0; y < h; y++ {
+		sy := sb.Min.Y + y*sb.Dy()/h
+		for x := 0; x < w; x++ {
+			dst.Set(x0+x, y0+y, src.At(sb.Min.X+x*sb.Dx()/w, sy))
+		}
+	}
+}
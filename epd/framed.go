@@ -0,0 +1,77 @@
+package epd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Framed stream format read by DrawFramed, all integers big-endian:
+//
+//	offset  size  field
+//	0       4     magic "EPDF"
+//	4       2     width in pixels
+//	6       2     height in pixels
+//	8       1     mode, FrameMode1Bit
+//	9       n     payload, ((width+7)/8)*height bytes
+//
+// The payload is a frame exactly as returned by EncodeForDisplay: rows top
+// to bottom in the panel's native portrait orientation, MSB = leftmost
+// pixel, bit set = white.
+const (
+	frameMagic      = "EPDF"
+	frameHeaderSize = 9
+
+	// FrameMode1Bit is the only payload mode: one bit per pixel.
+	FrameMode1Bit byte = 0
+)
+
+// EncodeFramed prefixes a frame buffer from EncodeForDisplay with the
+// header DrawFramed expects, so clients using this package can produce the
+// stream directly.
+func (d *Display) EncodeFramed(buf []byte) ([]byte, error) {
+	if len(buf) != d.BufferSize() {
+		return nil, fmt.Errorf("buffer length %d does not match frame size %d", len(buf), d.BufferSize())
+	}
+	out := make([]byte, frameHeaderSize, frameHeaderSize+len(buf))
+	copy(out, frameMagic)
+	binary.BigEndian.PutUint16(out[4:], uint16(d.width))
+	binary.BigEndian.PutUint16(out[6:], uint16(d.height))
+	out[8] = FrameMode1Bit
+	return append(out, buf...), nil
+}
+
+// DrawFramed reads one framed frame from r and shows it with a full
+// refresh via DrawBuffer. The header must match the panel's native size;
+// nothing is sent to the panel unless the whole payload was read.
+func (d *Display) DrawFramed(r io.Reader) error {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("truncated frame header: %w", err)
+		}
+		return fmt.Errorf("frame header read failed: %w", err)
+	}
+
+	if string(header[:4]) != frameMagic {
+		return fmt.Errorf("invalid frame header: magic % x, want %q", header[:4], frameMagic)
+	}
+	width := int(binary.BigEndian.Uint16(header[4:]))
+	height := int(binary.BigEndian.Uint16(header[6:]))
+	if width != d.width || height != d.height {
+		return fmt.Errorf("frame is %dx%d, panel is %dx%d", width, height, d.width, d.height)
+	}
+	if mode := header[8]; mode != FrameMode1Bit {
+		return fmt.Errorf("unsupported frame mode %d", mode)
+	}
+
+	buf := make([]byte, d.BufferSize())
+	if n, err := io.ReadFull(r, buf); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("truncated frame payload: got %d of %d bytes", n, len(buf))
+		}
+		return fmt.Errorf("frame payload read failed: %w", err)
+	}
+	return d.DrawBuffer(buf)
+}
@@ -0,0 +1,66 @@
+package epd
+
+import "time"
+
+// InitStep is one command of a controller init sequence. Data bytes follow
+// the command; then the driver optionally waits for BUSY to assert
+// (ExpectBusy, which fails New with ErrNoResponse if it never does), for it
+// to release (WaitBusy), and for Delay.
+type InitStep struct {
+	Command    byte
+	Data       []byte
+	ExpectBusy bool
+	WaitBusy   bool
+	Delay      time.Duration
+}
+
+// InitSequence returns the built-in init table for the configured model and
+// settings, as run after the hardware reset. It is a starting point for a
+// custom DisplayConfig.InitSequence.
+func (d *Display) InitSequence() ([]InitStep, error) {
+	return d.ctrl.initSteps()
+}
+
+// runInit runs DisplayConfig.InitSequence if one is set, otherwise the
+// table produced by builtin.
+func (d *Display) runInit(builtin func() ([]InitStep, error)) error {
+	steps := d.config.InitSequence
+	if steps == nil {
+		var err error
+		if steps, err = builtin(); err != nil {
+			return err
+		}
+	}
+	if err := d.runSteps(steps); err != nil {
+		return err
+	}
+	d.border = d.config.Border
+	return nil
+}
+
+func (d *Display) runSteps(steps []InitStep) error {
+	for _, step := range steps {
+		if err := d.sendCommand(step.Command); err != nil {
+			return err
+		}
+		for _, b := range step.Data {
+			if err := d.sendData(b); err != nil {
+				return err
+			}
+		}
+		if step.ExpectBusy {
+			if err := d.expectBusy(); err != nil {
+				return err
+			}
+		}
+		if step.WaitBusy {
+			if err := d.waitBusy(); err != nil {
+				return err
+			}
+		}
+		if step.Delay > 0 {
+			d.clock.Sleep(step.Delay)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Canvas is a white, panel-sized grayscale framebuffer handed to
+// DrawLayout callbacks. It is a draw.Image, so anything from image/draw
+// works on it, and adds helpers for the common layout pieces.
+type Canvas struct {
+	*image.Gray
+}
+
+// DrawImage composites img with its top-left corner at at. Transparent
+// pixels leave the canvas unchanged.
+func (c *Canvas) DrawImage(img image.Image, at image.Point) {
+	b := img.Bounds()
+	draw.Draw(c, image.Rectangle{Min: at, Max: at.Add(b.Size())}, img, b.Min, draw.Over)
+}
+
+// DrawText draws s in black with the built-in font, top-left at at.
+func (c *Canvas) DrawText(s string, at image.Point, scale int) {
+	drawText(c, at, s, scale, color.Black)
+}
+
+// DrawTextCentered draws lines centered in area, as large as fits up to
+// maxScale.
+func (c *Canvas) DrawTextCentered(lines []string, area image.Rectangle, maxScale int) {
+	drawCenteredLines(c, area, lines, maxScale)
+}
+
+// FillRect fills r with col.
+func (c *Canvas) FillRect(r image.Rectangle, col color.Color) {
+	draw.Draw(c, r, image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+// DrawLayout composes a portrait frame in fn and shows it with a single
+// full refresh.
+func (d *Display) DrawLayout(fn func(c *Canvas)) error {
+	c := newCanvas(d.RequiredBounds())
+	fn(c)
+	return d.DrawPortrait(c.Gray)
+}
+
+// DrawLayoutLandscape is DrawLayout on a landscape canvas.
+func (d *Display) DrawLayoutLandscape(fn func(c *Canvas)) error {
+	c := newCanvas(image.Rect(0, 0, d.height, d.width))
+	fn(c)
+	return d.DrawLandscape(c.Gray)
+}
+
+func newCanvas(bounds image.Rectangle) *Canvas {
+	gray := image.NewGray(bounds)
+	draw.Draw(gray, bounds, image.White, image.Point{}, draw.Src)
+	return &Canvas{Gray: gray}
+}
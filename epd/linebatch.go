@@ -0,0 +1,84 @@
+package epd
+
+import (
+	"errors"
+	"image"
+)
+
+// ErrNoBatch is returned by EndBatch without a matching BeginBatch.
+var ErrNoBatch = errors.New("no line batch in progress")
+
+// lineBatch collects the partial updates made between BeginBatch and
+// EndBatch.
+type lineBatch struct {
+	depth  int
+	buf    []byte
+	region image.Rectangle
+}
+
+// BeginBatch starts collecting UpdateLine and UpdateRegion calls instead of
+// refreshing each one. EndBatch then sends them as a single partial refresh
+// covering the union of their regions. Batches nest; only the outermost
+// EndBatch refreshes.
+func (d *Display) BeginBatch() {
+	d.batchMu.Lock()
+	d.batch.depth++
+	d.batchMu.Unlock()
+}
+
+// EndBatch ends the batch started by BeginBatch and refreshes the union of
+// the collected regions. The union is a bounding box, so pixels inside it
+// that no batched call covered are resent from the retained frame (or, if
+// nothing has been drawn yet, from the first batched image).
+func (d *Display) EndBatch() error {
+	d.batchMu.Lock()
+	if d.batch.depth == 0 {
+		d.batchMu.Unlock()
+		return ErrNoBatch
+	}
+	d.batch.depth--
+	if d.batch.depth > 0 || d.batch.buf == nil {
+		d.batchMu.Unlock()
+		return nil
+	}
+	buf, region := d.batch.buf, d.batch.region
+	d.batch.buf, d.batch.region = nil, image.Rectangle{}
+	d.batchMu.Unlock()
+
+	return d.refreshRegion(region, buf)
+}
+
+// stageRegion merges region of buf into the open batch. It reports false
+// when no batch is open and the caller should refresh directly.
+func (d *Display) stageRegion(region image.Rectangle, buf []byte) bool {
+	d.batchMu.Lock()
+	defer d.batchMu.Unlock()
+	if d.batch.depth == 0 {
+		return false
+	}
+
+	if d.batch.buf == nil {
+		// batchMu is always taken before opMu.
+		d.opMu.Lock()
+		if d.frame != nil {
+			d.batch.buf = append([]byte(nil), d.frame...)
+		}
+		d.opMu.Unlock()
+		if d.batch.buf == nil {
+			d.batch.buf = append([]byte(nil), buf...)
+		}
+	}
+
+	rows := region
+	if d.config.VerticalFlip {
+		rows = image.Rect(region.Min.X, d.height-region.Max.Y, region.Max.X, d.height-region.Min.Y)
+	}
+	lineWidth := d.LineWidth()
+	x0 := rows.Min.X / 8
+	x1 := (rows.Max.X + 7) / 8
+	for y := rows.Min.Y; y < rows.Max.Y; y++ {
+		copy(d.batch.buf[y*lineWidth+x0:y*lineWidth+x1], buf[y*lineWidth+x0:y*lineWidth+x1])
+	}
+	d.batch.region = d.batch.region.Union(region)
+	return true
+}
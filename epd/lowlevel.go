@@ -0,0 +1,46 @@
+package epd
+
+// SendCommand writes a raw controller command byte. It is an advanced,
+// unsafe escape hatch for experimenting with new panels and waveforms:
+// nothing is validated and the driver's view of the panel state (frame,
+// RAM window, standby) is not updated.
+func (d *Display) SendCommand(cmd byte) error {
+	if err := d.openLocked(); err != nil {
+		return err
+	}
+	return d.sendCommand(cmd)
+}
+
+// SendData writes raw data bytes following a SendCommand. Like SendCommand
+// it bypasses all driver bookkeeping, including BitOrder, and is intended
+// for experimentation.
+func (d *Display) SendData(data ...byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := d.openLocked(); err != nil {
+		return err
+	}
+	if len(data) == 1 {
+		return d.sendData(data[0])
+	}
+	return d.sendRawDataBulk(data)
+}
+
+// WaitBusy blocks until the controller reports ready or RefreshTimeout
+// elapses. Use it after raw commands that trigger a busy period.
+func (d *Display) WaitBusy() error {
+	if err := d.openLocked(); err != nil {
+		return err
+	}
+	return d.waitBusy()
+}
+
+// openLocked opens the bus for the raw calls under opMu, so a LazyInit
+// open cannot race a draw opening it too. The raw transfer that follows
+// runs without the lock, as documented on withRecovery.
+func (d *Display) openLocked() error {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+	return d.ensureOpen()
+}
@@ -0,0 +1,32 @@
+package epd
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestLazyOpenConcurrent checks, under -race, that the raw calls open a
+// LazyInit bus under the same lock as the first draw.
+func TestLazyOpenConcurrent(t *testing.T) {
+	config := DefaultConfig()
+	config.LazyInit = true
+	d := newDryRunDisplay(t, config)
+
+	var wg sync.WaitGroup
+	for _, op := range []func() error{
+		func() error { return d.DrawImage(whiteImage(d)) },
+		func() error { return d.SendCommand(cmdSetRamXCounter) },
+		func() error { return d.SendData(0x00, 0x00) },
+		d.WaitBusy,
+		func() error { d.Busy(); return nil },
+	} {
+		wg.Add(1)
+		go func(op func() error) {
+			defer wg.Done()
+			if err := op(); err != nil {
+				t.Error(err)
+			}
+		}(op)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,27 @@
+package epd
+
+import "time"
+
+type Metrics interface {
+	IncRefresh()
+	ObserveRefreshDuration(d time.Duration)
+	IncTimeout()
+	IncSPIError()
+}
+
+// RefreshStats returns how many full and partial refreshes this Display has
+// performed, starting from InitialFullRefreshes and InitialPartialRefreshes.
+// Persist the values and feed them back through the config to track panel
+// wear across restarts.
+func (d *Display) RefreshStats() (full, partial int) {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+	return d.fullRefreshes, d.partialRefreshes
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) IncRefresh()                          {}
+func (noopMetrics) ObserveRefreshDuration(time.Duration) {}
+func (noopMetrics) IncTimeout()                          {}
+func (noopMetrics) IncSPIError()                         {}
@@ -0,0 +1,180 @@
+package epd
+
+import (
+	"fmt"
+	"image"
+)
+
+// UpdateLine partially refreshes the horizontal band [y, y+height) of img.
+// The band is given in the image's own orientation, so on a landscape image
+// it spans the full landscape width.
+func (d *Display) UpdateLine(y, height int, img image.Image) error {
+	fitted := d.fitOversize(img)
+	size := fitted.Bounds().Size()
+	if height <= 0 || y < 0 || y+height > size.Y {
+		return fmt.Errorf("line band y=%d height=%d out of bounds for image height %d", y, height, size.Y)
+	}
+	return d.UpdateRegion(image.Rect(0, y, size.X, y+height), img)
+}
+
+// UpdateRegion partially refreshes region of img. The region is in the
+// logical coordinates of img: for a landscape image it is mapped through the
+// same rotation DrawImage applies, and the controller then maps it onto the
+// physical RAM window for the configured mirroring. The refreshed area is
+// widened to whole bytes after the transform. Between BeginBatch and
+// EndBatch the update is collected instead of sent.
+func (d *Display) UpdateRegion(region image.Rectangle, img image.Image) error {
+	fitted := d.fitOversize(img)
+	size := fitted.Bounds().Size()
+	logical := image.Rect(0, 0, size.X, size.Y)
+	if region.Empty() || !region.In(logical) {
+		return fmt.Errorf("region %v out of bounds for image %dx%d", region, size.X, size.Y)
+	}
+
+	buf, err := d.encodeImage(fitted)
+	if err != nil {
+		return err
+	}
+	panel := d.logicalToPanel(region, size)
+	if d.stageRegion(panel, buf) {
+		return nil
+	}
+	return d.refreshRegion(panel, buf)
+}
+
+// logicalToPanel maps a region of an image of the given size onto the
+// portrait panel coordinates used by the frame buffer.
+func (d *Display) logicalToPanel(r image.Rectangle, size image.Point) image.Rectangle {
+	if d.isLandscape(size.X, size.Y, orientAuto) {
+		return image.Rect(r.Min.Y, d.height-r.Max.X, r.Max.Y, d.height-r.Min.X)
+	}
+	return r
+}
+
+func (d *Display) refreshRegion(region image.Rectangle, buf []byte) error {
+	return d.refreshRegionFrom(region, buf, nil)
+}
+
+// refreshRegionOrFull is refreshRegion on models with partial refresh. On
+// the others region of buf is composited over the retained frame and shown
+// with a full refresh.
+func (d *Display) refreshRegionOrFull(region image.Rectangle, buf []byte) error {
+	if d.Capabilities().PartialRefresh {
+		return d.refreshRegion(region, buf)
+	}
+	return d.withRecovery(func() error {
+		next := buf
+		if d.frame != nil {
+			next = append([]byte(nil), d.frame...)
+			d.copyFrameRect(next, buf, region)
+		}
+		return d.writeFrame(next, d.landscape)
+	})
+}
+
+// refreshRegionFrom is refreshRegion against an explicit previous frame:
+// when old is set it replaces the retained frame, under the same lock as
+// the write, before region is diffed against it.
+func (d *Display) refreshRegionFrom(region image.Rectangle, buf, old []byte) error {
+	if err := d.checkPartial(); err != nil {
+		return err
+	}
+	return d.withRecovery(func() error {
+		if old != nil {
+			d.frame = old
+		}
+		return d.refreshRegionLocked(region, buf)
+	})
+}
+
+// refreshRegionLocked is refreshRegion for callers that already run under
+// withRecovery, so they can compose buf from the retained frame and write
+// it without another operation slipping in between.
+func (d *Display) refreshRegionLocked(region image.Rectangle, buf []byte) error {
+	if err := d.checkPartial(); err != nil {
+		return err
+	}
+	if err := d.captureFrame(buf); err != nil {
+		return err
+	}
+	if d.config.VerticalFlip {
+		region = image.Rect(region.Min.X, d.height-region.Max.Y, region.Max.X, d.height-region.Min.Y)
+	}
+	return d.writeRegion(region, buf)
+}
+
+func (d *Display) checkPartial() error {
+	if !d.Capabilities().PartialRefresh {
+		return fmt.Errorf("model %v does not support partial refresh", d.config.Model)
+	}
+	return nil
+}
+
+// writeRegion sends region of buf against the retained frame and runs the
+// partial update. Without a retained frame there is nothing to diff
+// against, so buf, which always holds a whole frame, is written with a full
+// refresh instead and becomes the baseline for later partial updates. It
+// reads and updates d.frame, so it runs under withRecovery.
+func (d *Display) writeRegion(region image.Rectangle, buf []byte) error {
+	if d.frame == nil {
+		return d.writeFrame(buf, d.landscape)
+	}
+
+	lineWidth := d.LineWidth()
+	x0 := region.Min.X / 8
+	x1 := (region.Max.X + 7) / 8
+
+	newBand := make([]byte, 0, (x1-x0)*region.Dy())
+	oldBand := make([]byte, 0, (x1-x0)*region.Dy())
+	for y := region.Min.Y; y < region.Max.Y; y++ {
+		newBand = append(newBand, buf[y*lineWidth+x0:y*lineWidth+x1]...)
+		oldBand = append(oldBand, d.frame[y*lineWidth+x0:y*lineWidth+x1]...)
+	}
+
+	aligned := image.Rect(x0*8, region.Min.Y, x1*8, region.Max.Y)
+	if err := d.ensureAwake(); err != nil {
+		return err
+	}
+
+	start := d.clock.Now()
+	if err := d.ctrl.updatePartial(aligned, oldBand, newBand); err != nil {
+		return err
+	}
+	d.lastRefresh = d.clock.Now().Sub(start)
+	d.metrics.IncRefresh()
+	d.metrics.ObserveRefreshDuration(d.lastRefresh)
+	d.partialRefreshes++
+
+	for y := region.Min.Y; y < region.Max.Y; y++ {
+		copy(d.frame[y*lineWidth+x0:y*lineWidth+x1], buf[y*lineWidth+x0:y*lineWidth+x1])
+	}
+	return d.afterRefresh()
+}
+
+// DrawImagePartialPair partially refreshes region using an explicit previous
+// frame: old is written to the controller's old bank and next to the new one,
+// so the waveform is computed from the true panel state. Both images must
+// have the same panel size and orientation; region is in their coordinates.
+// The retained frame afterwards is old with region replaced from next.
+func (d *Display) DrawImagePartialPair(old, next image.Image, region image.Rectangle) error {
+	old, next = d.fitOversize(old), d.fitOversize(next)
+	size := next.Bounds().Size()
+	if old.Bounds().Size() != size {
+		return fmt.Errorf("old image %v and new image %v differ in size", old.Bounds().Size(), size)
+	}
+	logical := image.Rect(0, 0, size.X, size.Y)
+	if region.Empty() || !region.In(logical) {
+		return fmt.Errorf("region %v out of bounds for image %dx%d", region, size.X, size.Y)
+	}
+
+	oldBuf, err := d.encodeImage(old)
+	if err != nil {
+		return err
+	}
+	newBuf, err := d.encodeImage(next)
+	if err != nil {
+		return err
+	}
+
+	return d.refreshRegionFrom(d.logicalToPanel(region, size), newBuf, oldBuf)
+}
@@ -0,0 +1,103 @@
+package epd
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// thresholdEncoder is a custom Encoder producing the same buffer as the
+// built-in conversion, so its results can be compared with it.
+type thresholdEncoder struct{}
+
+func (thresholdEncoder) Encode(img image.Image, width, height int) ([]byte, error) {
+	lineWidth := (width + 7) / 8
+	buf := make([]byte, lineWidth*height)
+	bounds := img.Bounds()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray).Y >= 128 {
+				buf[y*lineWidth+x/8] |= 1 << uint(7-x%8)
+			}
+		}
+	}
+	return buf, nil
+}
+
+// whiteImage returns a white image of the panel's portrait size.
+func whiteImage(d *Display) *image.Gray {
+	img := image.NewGray(d.RequiredBounds())
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	return img
+}
+
+func TestUpdateRegionEncoderVerticalFlip(t *testing.T) {
+	var frames [][]byte
+	for _, encoder := range []Encoder{nil, thresholdEncoder{}} {
+		config := DefaultConfig()
+		config.VerticalFlip = true
+		config.Encoder = encoder
+		d := newDryRunDisplay(t, config)
+
+		img := whiteImage(d)
+		if err := d.DrawImage(img); err != nil {
+			t.Fatal(err)
+		}
+		draw.Draw(img, image.Rect(0, 0, 16, 4), image.Black, image.Point{}, draw.Src)
+		if err := d.UpdateRegion(image.Rect(0, 0, 16, 4), img); err != nil {
+			t.Fatal(err)
+		}
+		if wantBit(d, d.frame, 0, d.height-1) {
+			t.Errorf("encoder %T: top-left pixel not black in the flipped frame", encoder)
+		}
+		frames = append(frames, d.frame)
+	}
+	if !bytes.Equal(frames[0], frames[1]) {
+		t.Error("Encoder and built-in conversion retain different frames with VerticalFlip")
+	}
+}
+
+func TestUpdateRegionWithoutFrameWritesFull(t *testing.T) {
+	d, rec, _, err := NewRecordingDisplay(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.CloseWithoutSleep()
+	rec.Reset()
+
+	img := whiteImage(d)
+	draw.Draw(img, image.Rect(0, 0, 8, 1), image.Black, image.Point{}, draw.Src)
+	if err := d.UpdateRegion(image.Rect(0, 0, 8, 1), img); err != nil {
+		t.Fatal(err)
+	}
+	want, err := d.EncodeForDisplay(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.ExpectOps(
+		Op{Command: cmdSetRamXCounter, Data: []byte{0x00}},
+		Op{Command: cmdSetRamYCounter, Data: []byte{0x00, 0x00}},
+		Op{Command: cmdWriteRAM, Data: want},
+		Op{Command: cmdDisplayUpdateControl2, Data: []byte{displayUpdateSequenceNormalMode}},
+		Op{Command: displayUpdateSequence},
+	); err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(d.frame, want) {
+		t.Error("the first region update did not retain its frame")
+	}
+
+	rec.Reset()
+	if err := d.UpdateRegion(image.Rect(0, 0, 8, 1), whiteImage(d)); err != nil {
+		t.Fatal(err)
+	}
+	ops, err := rec.Ops()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) < 5 || ops[4].Command != cmdWriteOldRAM || !bytes.Equal(ops[4].Data, []byte{0x00}) {
+		t.Errorf("second region update did not diff against the retained frame: %v", ops)
+	}
+}
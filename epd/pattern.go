@@ -0,0 +1,25 @@
+package epd
+
+type Pattern [8]byte
+
+var (
+	PatternCheckerboard = Pattern{0xF0, 0xF0, 0xF0, 0xF0, 0x0F, 0x0F, 0x0F, 0x0F}
+	PatternDots25       = Pattern{0x77, 0xDD, 0x77, 0xDD, 0x77, 0xDD, 0x77, 0xDD}
+	PatternDots50       = Pattern{0xAA, 0x55, 0xAA, 0x55, 0xAA, 0x55, 0xAA, 0x55}
+	PatternDots75       = Pattern{0x88, 0x22, 0x88, 0x22, 0x88, 0x22, 0x88, 0x22}
+)
+
+// ClearPattern fills the panel with pattern, repeated every 8 rows, and
+// shows it with a full refresh. Like Clear it also resets the old bank, so
+// the next partial refresh starts from the pattern.
+func (d *Display) ClearPattern(pattern Pattern) error {
+	lineWidth := d.LineWidth()
+	buf := make([]byte, lineWidth*d.height)
+	for y := 0; y < d.height; y++ {
+		for x := 0; x < lineWidth; x++ {
+			buf[y*lineWidth+x] = pattern[y%len(pattern)]
+		}
+	}
+
+	return d.clearTo(buf)
+}
@@ -0,0 +1,37 @@
+package epd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestClearPatternWritesOldBank(t *testing.T) {
+	d, rec, _, err := NewRecordingDisplay(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.CloseWithoutSleep()
+	rec.Reset()
+
+	if err := d.ClearPattern(PatternDots50); err != nil {
+		t.Fatal(err)
+	}
+
+	want := bytes.Repeat([]byte{0xAA}, d.LineWidth())
+	want = append(want, bytes.Repeat([]byte{0x55}, d.LineWidth())...)
+	want = bytes.Repeat(want, d.height/2)
+	cursor := []Op{
+		{Command: cmdSetRamXCounter, Data: []byte{0x00}},
+		{Command: cmdSetRamYCounter, Data: []byte{0x00, 0x00}},
+	}
+	ops := append(append([]Op(nil), cursor...), Op{Command: cmdWriteOldRAM, Data: want})
+	ops = append(ops, cursor...)
+	ops = append(ops,
+		Op{Command: cmdWriteRAM, Data: want},
+		Op{Command: cmdDisplayUpdateControl2, Data: []byte{displayUpdateSequenceNormalMode}},
+		Op{Command: displayUpdateSequence},
+	)
+	if err := rec.ExpectOps(ops...); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,61 @@
+package epd
+
+import "log"
+
+// startPeriodicRefresh launches the PeriodicFullRefresh timer. Each tick
+// re-displays the last frame with a full refresh to clear slow ghosting on
+// always-on displays; ticks before the first frame do nothing.
+func (d *Display) startPeriodicRefresh() {
+	interval := d.config.PeriodicFullRefresh
+	if interval <= 0 {
+		return
+	}
+
+	d.periodicStop = make(chan struct{})
+	d.periodicDone = make(chan struct{})
+	go func() {
+		defer close(d.periodicDone)
+		for {
+			select {
+			case <-d.periodicStop:
+				return
+			case <-d.clock.After(interval):
+			}
+			if err := d.periodicRefresh(); err != nil {
+				d.logger().Printf("epd: periodic full refresh failed: %v", err)
+			}
+		}
+	}()
+}
+
+// stopPeriodicRefresh stops the timer and waits for a refresh in progress
+// to finish.
+func (d *Display) stopPeriodicRefresh() {
+	if d.periodicStop == nil {
+		return
+	}
+	close(d.periodicStop)
+	<-d.periodicDone
+	d.periodicStop = nil
+}
+
+// logger returns the configured Logger or the standard logger, for errors
+// from background goroutines that have no caller to return them to.
+func (d *Display) logger() *log.Logger {
+	if d.config.Logger != nil {
+		return d.config.Logger
+	}
+	return log.Default()
+}
+
+func (d *Display) periodicRefresh() error {
+	return d.withRecovery(func() error {
+		if d.frame == nil {
+			return nil
+		}
+		if err := d.writeRAM(d.frame); err != nil {
+			return d.abortWrite(err)
+		}
+		return d.update()
+	})
+}
@@ -0,0 +1,166 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// ImageStage is one step of DisplayConfig.Pipeline. Stages run in order on
+// the image passed to DrawImage, before it is fitted and encoded to 1 bit.
+type ImageStage func(image.Image) image.Image
+
+func (d *Display) applyPipeline(img image.Image) image.Image {
+	for _, stage := range d.config.Pipeline {
+		if stage != nil {
+			img = stage(img)
+		}
+	}
+	return img
+}
+
+// StageResize scales the image to fit within width x height, preserving the
+// aspect ratio and centering it on a white background.
+func StageResize(width, height int) ImageStage {
+	return func(img image.Image) image.Image {
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+		scaleInto(dst, dst.Bounds(), img)
+		return dst
+	}
+}
+
+// StageGamma converts the image to grayscale and applies gamma correction.
+// Values above 1 brighten mid-tones, values below 1 darken them.
+func StageGamma(gamma float64) ImageStage {
+	var table [256]uint8
+	for i := range table {
+		table[i] = uint8(math.Round(255 * math.Pow(float64(i)/255, 1/gamma)))
+	}
+	return func(img image.Image) image.Image {
+		gray := toGray(img)
+		for i, v := range gray.Pix {
+			gray.Pix[i] = table[v]
+		}
+		return gray
+	}
+}
+
+// StageThreshold converts the image to pure black and white, mapping gray
+// levels below level to black.
+func StageThreshold(level uint8) ImageStage {
+	return func(img image.Image) image.Image {
+		gray := toGray(img)
+		for i, v := range gray.Pix {
+			if v < level {
+				gray.Pix[i] = 0
+			} else {
+				gray.Pix[i] = 0xFF
+			}
+		}
+		return gray
+	}
+}
+
+// StageDither converts the image to black and white using Floyd-Steinberg
+// error diffusion.
+func StageDither() ImageStage {
+	return func(img image.Image) image.Image {
+		gray := toGray(img)
+		w, h := gray.Rect.Dx(), gray.Rect.Dy()
+		errs := make([]int, w*h)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				i := y*gray.Stride + x
+				v := int(gray.Pix[i]) + errs[y*w+x]
+				out := 0
+				if v >= 128 {
+					out = 0xFF
+				}
+				gray.Pix[i] = uint8(out)
+
+				e := v - out
+				if x+1 < w {
+					errs[y*w+x+1] += e * 7 / 16
+				}
+				if y+1 < h {
+					if x > 0 {
+						errs[(y+1)*w+x-1] += e * 3 / 16
+					}
+					errs[(y+1)*w+x] += e * 5 / 16
+					if x+1 < w {
+						errs[(y+1)*w+x+1] += e / 16
+					}
+				}
+			}
+		}
+		return gray
+	}
+}
+
+// StageInvert converts the image to grayscale and inverts it.
+func StageInvert() ImageStage {
+	return func(img image.Image) image.Image {
+		gray := toGray(img)
+		for i, v := range gray.Pix {
+			gray.Pix[i] = ^v
+		}
+		return gray
+	}
+}
+
+// toGray returns a fresh grayscale copy of img, rebased to a zero origin so
+// later stages can index Pix directly.
+func toGray(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			gray.SetGray(x, y, color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray))
+		}
+	}
+	return gray
+}
+
+// Rotation is a clockwise quarter-turn applied by StageRotate.
+type Rotation int
+
+const (
+	Rotate0 Rotation = iota
+	Rotate90
+	Rotate180
+	Rotate270
+)
+
+// StageRotate turns the image clockwise by r. Rotate90 and Rotate270 swap
+// its width and height.
+func StageRotate(r Rotation) ImageStage {
+	return func(img image.Image) image.Image {
+		if r == Rotate0 {
+			return img
+		}
+		bounds := img.Bounds()
+		w, h := bounds.Dx(), bounds.Dy()
+		size := image.Rect(0, 0, w, h)
+		if r == Rotate90 || r == Rotate270 {
+			size = image.Rect(0, 0, h, w)
+		}
+		dst := image.NewRGBA(size)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				var dx, dy int
+				switch r {
+				case Rotate90:
+					dx, dy = h-1-y, x
+				case Rotate180:
+					dx, dy = w-1-x, h-1-y
+				default:
+					dx, dy = y, w-1-x
+				}
+				dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+			}
+		}
+		return dst
+	}
+}
@@ -0,0 +1,97 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+)
+
+const plotMargin = 4
+
+type PlotOptions struct {
+	Window     int
+	ShowLabels bool
+}
+
+func (d *Display) Plot(samples []float64, opts PlotOptions) error {
+	if opts.Window > 0 && len(samples) > opts.Window {
+		samples = samples[len(samples)-opts.Window:]
+	}
+
+	canvas := image.NewGray(d.RequiredBounds())
+	draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+	drawPlot(canvas, canvas.Bounds().Inset(plotMargin), samples, opts.ShowLabels)
+
+	buf, err := d.encodeImage(canvas)
+	if err != nil {
+		return err
+	}
+	return d.showFrame(buf, false)
+}
+
+func drawPlot(dst draw.Image, area image.Rectangle, samples []float64, labels bool) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range samples {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+
+	if labels && lo <= hi {
+		maxLabel := strconv.FormatFloat(hi, 'g', 4, 64)
+		minLabel := strconv.FormatFloat(lo, 'g', 4, 64)
+		maxW, _ := textSize(maxLabel, 1)
+		minW, h := textSize(minLabel, 1)
+		drawText(dst, area.Min, maxLabel, 1, color.Black)
+		drawText(dst, image.Pt(area.Min.X, area.Max.Y-h), minLabel, 1, color.Black)
+		if minW > maxW {
+			maxW = minW
+		}
+		area.Min.X += maxW + plotMargin
+	}
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		dst.Set(area.Min.X, y, color.Black)
+	}
+	for x := area.Min.X; x < area.Max.X; x++ {
+		dst.Set(x, area.Max.Y-1, color.Black)
+	}
+
+	if lo > hi {
+		return
+	}
+
+	plot := image.Rect(area.Min.X+2, area.Min.Y, area.Max.X, area.Max.Y-3)
+	point := func(i int, v float64) image.Point {
+		x := plot.Min.X
+		if len(samples) > 1 {
+			x += i * (plot.Dx() - 1) / (len(samples) - 1)
+		}
+		y := plot.Min.Y + (plot.Dy()-1)/2
+		if hi > lo {
+			// Halving keeps v-lo and hi-lo finite for samples near the
+			// float64 limits.
+			y = plot.Max.Y - 1 - int((v/2-lo/2)/(hi/2-lo/2)*float64(plot.Dy()-1)+0.5)
+		}
+		return image.Pt(x, y)
+	}
+
+	prev, havePrev := image.Point{}, false
+	for i, v := range samples {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			havePrev = false
+			continue
+		}
+		p := point(i, v)
+		if havePrev {
+			drawLine(dst, prev.X, prev.Y, p.X, p.Y, color.Black)
+		} else {
+			dst.Set(p.X, p.Y, color.Black)
+		}
+		prev, havePrev = p, true
+	}
+}
@@ -0,0 +1,32 @@
+package epd
+
+import (
+	"image"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPlotSkipsInfiniteSamples(t *testing.T) {
+	d := newDryRunDisplay(t, DefaultConfig())
+	done := make(chan error, 1)
+	go func() {
+		done <- d.Plot([]float64{0, 1, math.Inf(1), 2, math.NaN(), math.MaxFloat64, -math.MaxFloat64}, PlotOptions{ShowLabels: true})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Plot with infinite samples did not return")
+	}
+
+	got := image.NewGray(image.Rect(0, 0, 64, 32))
+	drawPlot(got, got.Bounds(), []float64{0, math.Inf(1), 1}, false)
+	want := image.NewGray(got.Rect)
+	drawPlot(want, want.Bounds(), []float64{0, math.NaN(), 1}, false)
+	if string(got.Pix) != string(want.Pix) {
+		t.Error("an infinite sample was not skipped like NaN")
+	}
+}
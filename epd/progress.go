@@ -0,0 +1,47 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+const (
+	progressMargin    = 8
+	progressBarHeight = 16
+	progressLabelGap  = 6
+)
+
+// DrawProgress draws a centered progress bar filled to fraction, clamped to
+// 0..1, with an optional label above it. It uses a partial refresh where
+// the model supports one and a full refresh otherwise.
+func (d *Display) DrawProgress(fraction float64, label string) error {
+	if fraction < 0 || fraction != fraction {
+		fraction = 0
+	} else if fraction > 1 {
+		fraction = 1
+	}
+
+	canvas := image.NewGray(image.Rect(0, 0, d.width, d.height))
+	draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+
+	bar := image.Rect(progressMargin, (d.height-progressBarHeight)/2,
+		d.width-progressMargin, (d.height+progressBarHeight)/2)
+	drawRectOutline(canvas, bar, color.Black)
+
+	inner := bar.Inset(2)
+	fill := inner
+	fill.Max.X = inner.Min.X + int(float64(inner.Dx())*fraction+0.5)
+	draw.Draw(canvas, fill, image.Black, image.Point{}, draw.Src)
+
+	if label != "" {
+		w, h := textSize(label, 1)
+		drawText(canvas, image.Pt((d.width-w)/2, bar.Min.Y-progressLabelGap-h), label, 1, color.Black)
+	}
+
+	buf, err := d.encodeImage(canvas)
+	if err != nil {
+		return err
+	}
+	return d.refreshRegionOrFull(canvas.Bounds(), buf)
+}
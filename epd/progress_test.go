@@ -0,0 +1,31 @@
+package epd
+
+import "testing"
+
+// expectFullRefresh checks that rec holds exactly one full frame write and
+// refresh on an SSD1680.
+func expectFullRefresh(t *testing.T, rec *RecordingConn) {
+	t.Helper()
+	if err := rec.ExpectCommands(cmdSetRamXCounter, cmdSetRamYCounter, cmdWriteRAM, cmdDisplayUpdateControl2, displayUpdateSequence); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDrawProgressWithoutPartial(t *testing.T) {
+	config := DefaultConfig()
+	config.Model = ModelSSD1680Red
+	d, rec, _, err := NewRecordingDisplay(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.CloseWithoutSleep()
+	rec.Reset()
+
+	if err := d.DrawProgress(0.5, "half"); err != nil {
+		t.Fatal(err)
+	}
+	expectFullRefresh(t, rec)
+	if d.frame == nil {
+		t.Error("the progress frame was not retained")
+	}
+}
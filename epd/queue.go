@@ -0,0 +1,114 @@
+package epd
+
+import (
+	"errors"
+	"image"
+	"sync"
+)
+
+// QueuePolicy decides what RefreshQueue.Submit does when the queue is full.
+type QueuePolicy int
+
+const (
+	// QueueDropOldest discards the oldest queued frame to make room.
+	QueueDropOldest QueuePolicy = iota
+	// QueueCoalesce discards every queued frame, so only the newest one is
+	// shown next.
+	QueueCoalesce
+	// QueueBlock makes Submit wait until the worker frees a slot.
+	QueueBlock
+)
+
+// ErrQueueClosed is returned by Submit after the queue was closed.
+var ErrQueueClosed = errors.New("refresh queue is closed")
+
+// RefreshQueue serializes full refreshes from many producers onto a single
+// worker goroutine, so frames can be submitted faster than the panel
+// refreshes without blocking the producers (unless QueueBlock is chosen).
+// Refresh errors are written to the display's Logger.
+type RefreshQueue struct {
+	d      *Display
+	size   int
+	policy QueuePolicy
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	frames []image.Image
+	closed bool
+	done   chan struct{}
+}
+
+// NewRefreshQueue starts a worker that shows submitted frames with
+// DrawImage, holding at most size frames (at least 1) while it is busy.
+func (d *Display) NewRefreshQueue(size int, policy QueuePolicy) *RefreshQueue {
+	if size < 1 {
+		size = 1
+	}
+	q := &RefreshQueue{d: d, size: size, policy: policy, done: make(chan struct{})}
+	q.cond = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+// Submit queues img for display, applying the queue's policy when full.
+func (q *RefreshQueue) Submit(img image.Image) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && len(q.frames) >= q.size {
+		switch q.policy {
+		case QueueBlock:
+			q.cond.Wait()
+			continue
+		case QueueCoalesce:
+			q.frames = q.frames[:0]
+		default:
+			q.frames = q.frames[1:]
+		}
+	}
+	if q.closed {
+		return ErrQueueClosed
+	}
+	q.frames = append(q.frames, img)
+	q.cond.Broadcast()
+	return nil
+}
+
+// Len returns the number of frames waiting to be shown.
+func (q *RefreshQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.frames)
+}
+
+// Close stops accepting frames, shows the ones still queued and waits for
+// the worker to exit. It does not close the Display.
+func (q *RefreshQueue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+	<-q.done
+}
+
+func (q *RefreshQueue) run() {
+	defer close(q.done)
+	for {
+		q.mu.Lock()
+		for len(q.frames) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if len(q.frames) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		img := q.frames[0]
+		q.frames = q.frames[1:]
+		q.cond.Broadcast()
+		q.mu.Unlock()
+
+		if err := q.d.DrawImage(img); err != nil {
+			q.d.logger().Printf("epd: queued refresh failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package epd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+)
+
+// sniffFormat names the image format from its leading magic bytes, or
+// returns "" if none of the supported formats match.
+func sniffFormat(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")):
+		return "png"
+	case bytes.HasPrefix(header, []byte("\xff\xd8\xff")):
+		return "jpeg"
+	case bytes.HasPrefix(header, []byte("GIF87a")), bytes.HasPrefix(header, []byte("GIF89a")):
+		return "gif"
+	case bytes.HasPrefix(header, []byte("BM")):
+		return "bmp"
+	default:
+		return ""
+	}
+}
+
+// maxReaderScale bounds the images DrawImageReader decodes, as a multiple
+// of the panel's longer side, so a forged header cannot make it allocate
+// gigabytes. It leaves room for photos that OversizeBehavior scales down.
+const maxReaderScale = 32
+
+// DrawImageReader decodes an image from r and draws it. The format is
+// detected from the content, never from a file name: PNG, JPEG, GIF and
+// uncompressed BMP are supported. The whole image is decoded before anything
+// is sent to the panel. When the decoded image does not fit the panel the
+// error names the detected format and size. Images more than
+// maxReaderScale times the panel size in either direction are rejected from
+// their header, before any pixels are allocated.
+func (d *Display) DrawImageReader(r io.Reader) error {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(8)
+	format := sniffFormat(header)
+	if format == "" {
+		if len(header) == 0 {
+			return errors.New("truncated image stream: no data")
+		}
+		return fmt.Errorf("unsupported image stream: unrecognized header % x (want PNG, JPEG, GIF or BMP)", header)
+	}
+
+	// DecodeConfig only reads the header; replay what it consumed for the
+	// full decode.
+	decodeConfig, decode := decodeAnyConfig, decodeAny
+	if format == "bmp" {
+		decodeConfig, decode = decodeBMPConfig, decodeBMP
+	}
+	var consumed bytes.Buffer
+	config, err := decodeConfig(io.TeeReader(br, &consumed))
+	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return fmt.Errorf("truncated %s image stream: %w", format, err)
+		}
+		return fmt.Errorf("%s image decode failed: %w", format, err)
+	}
+	if limit := maxReaderScale * max(d.width, d.height); config.Width > limit || config.Height > limit {
+		return fmt.Errorf("%dx%d %s image is too large for %dx%d panel (limit %dx%d)", config.Width, config.Height, format, d.width, d.height, limit, limit)
+	}
+
+	img, err := decode(io.MultiReader(&consumed, br))
+	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return fmt.Errorf("truncated %s image stream: %w", format, err)
+		}
+		return fmt.Errorf("%s image decode failed: %w", format, err)
+	}
+
+	if err := d.DrawImage(img); err != nil {
+		size := img.Bounds().Size()
+		return fmt.Errorf("draw %dx%d %s image on %dx%d panel: %w", size.X, size.Y, format, d.width, d.height, err)
+	}
+	return nil
+}
+
+// decodeAnyConfig and decodeAny use the formats registered with the image
+// package, for everything but BMP.
+func decodeAnyConfig(r io.Reader) (image.Config, error) {
+	config, _, err := image.DecodeConfig(r)
+	return config, err
+}
+
+func decodeAny(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+// DrawImageFile loads an image from path and draws it like DrawImageReader.
+// The extension is ignored; the format comes from the file contents.
+func (d *Display) DrawImageFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := d.DrawImageReader(f); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
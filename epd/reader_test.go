@@ -0,0 +1,76 @@
+package epd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"strings"
+	"testing"
+)
+
+// bmpStream builds a 24 bpp BMP header for a width x height image followed
+// by data, which may be shorter than the header promises.
+func bmpStream(width, height int32, data []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("BM")
+	binary.Write(&b, binary.LittleEndian, uint32(54+len(data)))
+	binary.Write(&b, binary.LittleEndian, uint32(0))
+	binary.Write(&b, binary.LittleEndian, uint32(54))
+	binary.Write(&b, binary.LittleEndian, uint32(40))
+	binary.Write(&b, binary.LittleEndian, width)
+	binary.Write(&b, binary.LittleEndian, height)
+	binary.Write(&b, binary.LittleEndian, uint16(1))
+	binary.Write(&b, binary.LittleEndian, uint16(24))
+	b.Write(make([]byte, 24))
+	b.Write(data)
+	return b.Bytes()
+}
+
+func TestDrawImageReaderRejectsHugeHeader(t *testing.T) {
+	d := newDryRunDisplay(t, DefaultConfig())
+	for _, tc := range []struct {
+		width, height int32
+		want          string
+	}{
+		{0x7FFFFFFF, 0x7FFFFFFF, "too large"},
+		{20000, 100, "too large for"},
+	} {
+		err := d.DrawImageReader(bytes.NewReader(bmpStream(tc.width, tc.height, nil)))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%dx%d: got %v, want error containing %q", tc.width, tc.height, err, tc.want)
+		}
+	}
+}
+
+func TestBMPRejectsHugePalette(t *testing.T) {
+	for _, colors := range []uint32{257, 0x40000000, 0xFFFFFFFF} {
+		data := bmpStream(8, 8, nil)
+		binary.LittleEndian.PutUint16(data[28:], 8)
+		binary.LittleEndian.PutUint32(data[46:], colors)
+		if _, err := decodeBMPConfig(bytes.NewReader(data)); err == nil || !strings.Contains(err.Error(), "palette colors") {
+			t.Errorf("colors %d: got %v, want a palette size error", colors, err)
+		}
+	}
+}
+
+func TestDrawImageReaderBMP(t *testing.T) {
+	d := newDryRunDisplay(t, DefaultConfig())
+	size := d.RequiredBounds().Size()
+	stride := (size.X*3 + 3) &^ 3
+	data := bytes.Repeat([]byte{0xFF}, stride*size.Y)
+	if err := d.DrawImageReader(bytes.NewReader(bmpStream(int32(size.X), int32(size.Y), data))); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := decodeBMP(bytes.NewReader(bmpStream(int32(size.X), int32(size.Y), data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Size() != size {
+		t.Errorf("decoded %v, want %v", img.Bounds().Size(), size)
+	}
+
+	if _, _, err := image.DecodeConfig(bytes.NewReader(bmpStream(1, 1, nil))); err == nil {
+		t.Error("the BMP decoder is registered with the image package")
+	}
+}
@@ -0,0 +1,307 @@
+package epd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+)
+
+// externalPort wraps a conn handed to NewWithConn. Connect returns that
+// conn and Close leaves it open, since it belongs to the caller.
+type externalPort struct {
+	conn spi.Conn
+}
+
+func (p externalPort) String() string                      { return p.conn.String() }
+func (p externalPort) Close() error                        { return nil }
+func (p externalPort) LimitSpeed(f physic.Frequency) error { return nil }
+
+func (p externalPort) Connect(f physic.Frequency, mode spi.Mode, bits int) (spi.Conn, error) {
+	return p.conn, nil
+}
+
+// Transfer is one SPI write captured by RecordingConn. Command reports the
+// DC level during the write: low (command) or high (data).
+type Transfer struct {
+	Command bool
+	Data    []byte
+}
+
+// Op is a decoded command followed by all the data bytes sent after it.
+type Op struct {
+	Command byte
+	Data    []byte
+}
+
+func (o Op) String() string {
+	if len(o.Data) == 0 {
+		return fmt.Sprintf("0x%02X", o.Command)
+	}
+	return fmt.Sprintf("0x%02X % X", o.Command, o.Data)
+}
+
+// RecordingConn is an spi.Conn that records every write instead of sending
+// it, so the exact command sequence a Display produces can be checked
+// without hardware. DC is sampled on every write to tell commands from data.
+type RecordingConn struct {
+	// DC is the pin the Display drives as DC, typically a FakePin.
+	DC gpio.PinIn
+	// OnCommand, if set, is called after each recorded command byte, for
+	// example to pulse a fake BUSY pin.
+	OnCommand func(cmd byte)
+
+	mu        sync.Mutex
+	transfers []Transfer
+}
+
+// NewRecordingConn returns a RecordingConn that reads the DC level from dc.
+func NewRecordingConn(dc gpio.PinIn) *RecordingConn {
+	return &RecordingConn{DC: dc}
+}
+
+func (c *RecordingConn) String() string {
+	return "recording"
+}
+
+func (c *RecordingConn) Duplex() conn.Duplex {
+	return conn.Half
+}
+
+// Tx records w. Any read buffer is zero filled.
+func (c *RecordingConn) Tx(w, r []byte) error {
+	for i := range r {
+		r[i] = 0
+	}
+	if len(w) == 0 {
+		return nil
+	}
+
+	command := c.DC != nil && c.DC.Read() == gpio.Low
+	c.mu.Lock()
+	c.transfers = append(c.transfers, Transfer{Command: command, Data: append([]byte(nil), w...)})
+	c.mu.Unlock()
+
+	if command && c.OnCommand != nil {
+		for _, cmd := range w {
+			c.OnCommand(cmd)
+		}
+	}
+	return nil
+}
+
+func (c *RecordingConn) TxPackets(p []spi.Packet) error {
+	for _, pkt := range p {
+		if err := c.Tx(pkt.W, pkt.R); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Transcript returns a copy of the writes recorded so far, one per Tx.
+func (c *RecordingConn) Transcript() []Transfer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]Transfer, len(c.transfers))
+	for i, t := range c.transfers {
+		out[i] = Transfer{Command: t.Command, Data: append([]byte(nil), t.Data...)}
+	}
+	return out
+}
+
+// Reset discards the recorded writes, for example after init so a test
+// only sees the sequence of the operation under test.
+func (c *RecordingConn) Reset() {
+	c.mu.Lock()
+	c.transfers = nil
+	c.mu.Unlock()
+}
+
+// Ops decodes the transcript into commands with their data, merging
+// consecutive data writes. It fails if data was sent before any command.
+func (c *RecordingConn) Ops() ([]Op, error) {
+	var ops []Op
+	for i, t := range c.Transcript() {
+		if t.Command {
+			for _, cmd := range t.Data {
+				ops = append(ops, Op{Command: cmd})
+			}
+			continue
+		}
+		if len(ops) == 0 {
+			return nil, fmt.Errorf("transfer %d: %d data bytes before any command", i, len(t.Data))
+		}
+		last := &ops[len(ops)-1]
+		last.Data = append(last.Data, t.Data...)
+	}
+	return ops, nil
+}
+
+// ExpectOps checks that the decoded transcript is exactly want, and
+// describes the first difference otherwise.
+func (c *RecordingConn) ExpectOps(want ...Op) error {
+	got, err := c.Ops()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(got) && i < len(want); i++ {
+		if got[i].Command != want[i].Command || !bytes.Equal(got[i].Data, want[i].Data) {
+			return fmt.Errorf("op %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+	if len(got) != len(want) {
+		return fmt.Errorf("got %d ops, want %d", len(got), len(want))
+	}
+	return nil
+}
+
+// ExpectCommands checks that the transcript sent exactly the command bytes
+// cmds in order, ignoring their data.
+func (c *RecordingConn) ExpectCommands(cmds ...byte) error {
+	got, err := c.Ops()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(got) && i < len(cmds); i++ {
+		if got[i].Command != cmds[i] {
+			return fmt.Errorf("command %d: got 0x%02X, want 0x%02X", i, got[i].Command, cmds[i])
+		}
+	}
+	if len(got) != len(cmds) {
+		return fmt.Errorf("got %d commands, want %d", len(got), len(cmds))
+	}
+	return nil
+}
+
+// FakePin is an in-memory gpio.PinIO. Out levels are kept in History, and
+// Set or Pulse control what Read returns, so it can stand in for DC, CS,
+// RST and BUSY.
+type FakePin struct {
+	name string
+
+	mu      sync.Mutex
+	level   gpio.Level
+	pulse   *gpio.Level
+	history []gpio.Level
+}
+
+// NewFakePin returns a FakePin called name at the given level.
+func NewFakePin(name string, level gpio.Level) *FakePin {
+	return &FakePin{name: name, level: level}
+}
+
+func (p *FakePin) String() string                { return p.name }
+func (p *FakePin) Halt() error                   { return nil }
+func (p *FakePin) Name() string                  { return p.name }
+func (p *FakePin) Number() int                   { return -1 }
+func (p *FakePin) Function() string              { return "" }
+func (p *FakePin) Pull() gpio.Pull               { return gpio.PullNoChange }
+func (p *FakePin) DefaultPull() gpio.Pull        { return gpio.Float }
+func (p *FakePin) In(gpio.Pull, gpio.Edge) error { return nil }
+
+// WaitForEdge never sees an edge; it waits out the timeout, capped at a
+// millisecond, and reports false.
+func (p *FakePin) WaitForEdge(timeout time.Duration) bool {
+	if timeout < 0 || timeout > time.Millisecond {
+		timeout = time.Millisecond
+	}
+	time.Sleep(timeout)
+	return false
+}
+
+// Read returns the pending Pulse level once, otherwise the current level.
+func (p *FakePin) Read() gpio.Level {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pulse != nil {
+		level := *p.pulse
+		p.pulse = nil
+		return level
+	}
+	return p.level
+}
+
+// Out sets the level and appends it to History.
+func (p *FakePin) Out(l gpio.Level) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.level = l
+	p.history = append(p.history, l)
+	return nil
+}
+
+func (p *FakePin) PWM(gpio.Duty, physic.Frequency) error {
+	return errors.New("fake pin does not support PWM")
+}
+
+// Set changes the level Read returns without recording it in History, the
+// way an external device drives an input.
+func (p *FakePin) Set(l gpio.Level) {
+	p.mu.Lock()
+	p.level = l
+	p.mu.Unlock()
+}
+
+// Pulse makes the next Read return l, after which the pin reads its level
+// again. Pulsing a fake BUSY pin after every command satisfies both the
+// driver's check that BUSY asserts and its wait for it to release.
+func (p *FakePin) Pulse(l gpio.Level) {
+	p.mu.Lock()
+	p.pulse = &l
+	p.mu.Unlock()
+}
+
+// History returns every level passed to Out, in order.
+func (p *FakePin) History() []gpio.Level {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]gpio.Level(nil), p.history...)
+}
+
+// RecordingPins are the fake pins NewRecordingDisplay wires up.
+type RecordingPins struct {
+	DC, CS, RST, BUSY *FakePin
+}
+
+// NewRecordingDisplay builds a Display on a RecordingConn and fake pins.
+// BUSY idles at the model's ready level and pulses busy after every
+// command, so init and refreshes complete immediately. The Display is
+// initialized like NewWithConn; call Reset on the conn to drop the init
+// sequence from the transcript.
+func NewRecordingDisplay(config DisplayConfig) (*Display, *RecordingConn, RecordingPins, error) {
+	if config.BulkSPIFrequency != 0 {
+		return nil, nil, RecordingPins{}, errors.New("bulk SPI frequency needs a port and is not supported with NewRecordingDisplay")
+	}
+	d, err := newDisplay(config)
+	if err != nil {
+		return nil, nil, RecordingPins{}, err
+	}
+
+	active := d.busyActiveLevel()
+	pins := RecordingPins{
+		DC:   NewFakePin(config.DCPin, gpio.High),
+		RST:  NewFakePin(config.RSTPin, gpio.High),
+		BUSY: NewFakePin(config.BUSYPin, !active),
+	}
+	var cs gpio.PinOut
+	if config.CSPin != "" {
+		pins.CS = NewFakePin(config.CSPin, gpio.High)
+		cs = pins.CS
+	}
+
+	rec := NewRecordingConn(pins.DC)
+	rec.OnCommand = func(byte) { pins.BUSY.Pulse(active) }
+	if err := d.attachConn(rec, pins.DC, cs, pins.RST, pins.BUSY); err != nil {
+		return nil, nil, RecordingPins{}, err
+	}
+
+	d.startPeriodicRefresh()
+	return d, rec, pins, nil
+}
@@ -0,0 +1,81 @@
+package epd
+
+import (
+	"reflect"
+	"testing"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+func TestInitTranscript(t *testing.T) {
+	for _, tc := range []struct {
+		model Model
+		want  []Op
+	}{
+		{ModelSSD1680, []Op{
+			{Command: cmdSoftwareReset},
+			{Command: cmdDriverOutputControl, Data: []byte{0xF9, 0x00, 0x00}},
+			{Command: cmdDataEntryMode, Data: []byte{0x03}},
+			{Command: cmdSetRamXStartEndPos, Data: []byte{0x00, 0x0F}},
+			{Command: cmdSetRamYStartEndPos, Data: []byte{0x00, 0x00, 0xF9, 0x00}},
+			{Command: cmdSetRamXCounter, Data: []byte{0x00}},
+			{Command: cmdSetRamYCounter, Data: []byte{0x00, 0x00}},
+			{Command: cmdBorderWaveformControl, Data: []byte{0x05}},
+			{Command: cmdDisplayUpdateControl1, Data: []byte{0x00, 0x80}},
+			{Command: cmdTempSensorControl, Data: []byte{0x80}},
+		}},
+		{ModelUC8151, []Op{
+			{Command: cmdPowerSetting, Data: []byte{0x03, 0x00, 0x2B, 0x2B, 0x03}},
+			{Command: cmdBoosterSoftStart, Data: []byte{0x17, 0x17, 0x17}},
+			{Command: cmdPowerOn},
+			{Command: cmdPanelSetting, Data: []byte{0x1F, 0x0D}},
+			{Command: cmdPLLControl, Data: []byte{0x3A}},
+			{Command: cmdResolutionSetting, Data: []byte{0x68, 0x00, 0xD4}},
+			{Command: cmdVCOMDCSetting, Data: []byte{0x28}},
+			{Command: cmdVCOMDataInterval, Data: []byte{0x97}},
+		}},
+	} {
+		config := DefaultConfig()
+		config.Model = tc.model
+		d, rec, pins, err := NewRecordingDisplay(config)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.model, err)
+		}
+		if err := rec.ExpectOps(tc.want...); err != nil {
+			t.Errorf("%v: %v", tc.model, err)
+		}
+		if got, want := pins.RST.History(), []gpio.Level{gpio.High, gpio.Low, gpio.High}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: RST levels %v, want %v", tc.model, got, want)
+		}
+		d.Close()
+	}
+}
+
+func TestSleepTranscript(t *testing.T) {
+	for _, tc := range []struct {
+		model Model
+		want  []Op
+	}{
+		{ModelSSD1680, []Op{{Command: cmdEnterDeepSleep, Data: []byte{0x01}}}},
+		{ModelUC8151, []Op{
+			{Command: cmdVCOMDataInterval, Data: []byte{0xF7}},
+			{Command: cmdPowerOff},
+			{Command: cmdDeepSleep, Data: []byte{0xA5}},
+		}},
+	} {
+		config := DefaultConfig()
+		config.Model = tc.model
+		d, rec, _, err := NewRecordingDisplay(config)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.model, err)
+		}
+		rec.Reset()
+		if err := d.Sleep(); err != nil {
+			t.Fatalf("%v: %v", tc.model, err)
+		}
+		if err := rec.ExpectOps(tc.want...); err != nil {
+			t.Errorf("%v: %v", tc.model, err)
+		}
+		d.CloseWithoutSleep()
+	}
+}
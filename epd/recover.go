@@ -0,0 +1,31 @@
+package epd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// withRecovery runs op and, when AutoRecover is set and op failed because
+// BUSY never released, resets and re-initializes the controller and runs op
+// once more. The reset discards whatever the controller was doing, so op
+// must rewrite everything it depends on.
+//
+// Every driver operation that talks to the panel goes through here, so it
+// also serializes refreshes with the PeriodicFullRefresh goroutine and the
+// runtime setters. The raw SendCommand and SendData escape hatches are the
+// exception: they bypass the driver and are not serialized.
+func (d *Display) withRecovery(op func() error) error {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+
+	err := op()
+	if err == nil || !d.config.AutoRecover || !errors.Is(err, ErrBusyTimeout) {
+		return err
+	}
+
+	if initErr := d.ctrl.init(); initErr != nil {
+		return fmt.Errorf("recovery after busy timeout failed: %w", initErr)
+	}
+	d.inStandby = false
+	return op()
+}
@@ -0,0 +1,164 @@
+package epd
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+type orientation int
+
+const (
+	orientAuto orientation = iota
+	orientPortrait
+	orientLandscape
+)
+
+// isLandscape reports whether an image of the given size is drawn rotated.
+// o is forced by DrawPortrait and DrawLandscape; with orientAuto a square
+// panel is always treated as portrait.
+func (d *Display) isLandscape(width, height int, o orientation) bool {
+	if width != d.height || height != d.width {
+		return false
+	}
+	switch o {
+	case orientPortrait:
+		return false
+	case orientLandscape:
+		return true
+	default:
+		return width != height
+	}
+}
+
+// DrawPortrait draws an image that must be exactly the panel's portrait
+// size, without rotation.
+func (d *Display) DrawPortrait(img image.Image) error {
+	return d.drawOriented(img, orientPortrait, d.width, d.height)
+}
+
+// DrawLandscape draws an image that must be exactly the panel's landscape
+// size, rotating it onto the panel.
+func (d *Display) DrawLandscape(img image.Image) error {
+	return d.drawOriented(img, orientLandscape, d.height, d.width)
+}
+
+func (d *Display) drawOriented(img image.Image, o orientation, width, height int) error {
+	prepared := d.prepareImage(img)
+	if size := prepared.Bounds().Size(); size.X != width || size.Y != height {
+		return fmt.Errorf("invalid image dimensions %dx%d: must be %dx%d", size.X, size.Y, width, height)
+	}
+	return d.drawPrepared(prepared, o)
+}
+
+type columnWriter interface {
+	beginWriteRAMColumns() error
+	endWriteRAMColumns() error
+}
+
+// RotationMode selects how landscape images are rotated onto the panel.
+// Portrait images are never rotated, so the mode only affects landscape
+// draws.
+type RotationMode int
+
+const (
+	// RotationAuto uses column writes (see RotationHardware) when the
+	// controller supports them and no option needs the rotated image in
+	// memory, otherwise software rotation.
+	RotationAuto RotationMode = iota
+	// RotationHardware always writes landscape images column by column,
+	// with the controller's data entry mode set to step down each column
+	// before moving across. The controller only changes the order the bytes
+	// are stored in: the pixels are still packed into column bytes in
+	// software, but straight from the source image, without the rotated
+	// copy RotationSoftware makes. Only the SSD1680 supports it, and it
+	// cannot be combined with Encoder or VerticalFlip; landscape draws fail
+	// otherwise.
+	RotationHardware
+	// RotationSoftware always rotates landscape images in memory before the
+	// usual row-by-row write. It works on every model and with every option.
+	RotationSoftware
+)
+
+// columnRotation returns the column writer to use for a landscape draw, or
+// false if the image must be rotated in software.
+func (d *Display) columnRotation() (columnWriter, bool, error) {
+	if d.config.RotationMode == RotationSoftware {
+		return nil, false, nil
+	}
+	cw, ok := d.ctrl.(columnWriter)
+	if ok && d.config.Encoder == nil && !d.config.VerticalFlip {
+		return cw, true, nil
+	}
+	if d.config.RotationMode == RotationHardware {
+		return nil, false, fmt.Errorf("hardware rotation not available on %v with the current options", d.config.Model)
+	}
+	return nil, false, nil
+}
+
+// drawLandscapeColumns writes a landscape image column by column. Each
+// byte packs eight vertically adjacent source pixels, which land side by
+// side in one panel row; it is sent in column order and kept in row order
+// as the retained frame.
+func (d *Display) drawLandscapeColumns(img image.Image, cw columnWriter) error {
+	lineWidth := d.LineWidth()
+	columns := make([]byte, lineWidth*d.height)
+	frame := make([]byte, len(columns))
+
+	white := d.landscapePixel(img)
+	for k := 0; k < lineWidth; k++ {
+		for py := 0; py < d.height; py++ {
+			var b byte
+			for bit := 0; bit < 8 && k*8+bit < d.width; bit++ {
+				if white(k*8+bit, py) {
+					b |= 1 << uint(7-bit)
+				}
+			}
+			columns[k*d.height+py] = b
+			frame[py*lineWidth+k] = b
+		}
+	}
+	if err := d.captureFrame(frame); err != nil {
+		return err
+	}
+
+	if err := d.ensureAwake(); err != nil {
+		return err
+	}
+	if err := cw.beginWriteRAMColumns(); err != nil {
+		return err
+	}
+	if err := d.sendDataBulk(columns); err != nil {
+		if endErr := cw.endWriteRAMColumns(); endErr != nil {
+			return fmt.Errorf("%w: %w (restoring data entry mode failed: %v)", ErrFrameNotDisplayed, err, endErr)
+		}
+		return d.abortWrite(err)
+	}
+	if err := cw.endWriteRAMColumns(); err != nil {
+		return err
+	}
+	d.frame = frame
+	d.landscape = true
+
+	return d.update()
+}
+
+// landscapePixel returns whether panel pixel (px, py) is white for a
+// landscape image rotated onto the panel, reading gray images directly.
+func (d *Display) landscapePixel(img image.Image) func(px, py int) bool {
+	bounds := img.Bounds()
+	right := bounds.Max.X - 1
+	if gray, ok := img.(*image.Gray); ok && d.config.PixelMapper == nil {
+		return func(px, py int) bool {
+			return gray.Pix[gray.PixOffset(right-py, bounds.Min.Y+px)] >= 0x80
+		}
+	}
+	palette := color.Palette{color.Black, color.White}
+	return func(px, py int) bool {
+		c := img.At(right-py, bounds.Min.Y+px)
+		if d.config.PixelMapper != nil {
+			return !d.config.PixelMapper(px, py, c)
+		}
+		return palette.Index(c) == 1
+	}
+}
@@ -0,0 +1,164 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+	"testing"
+)
+
+// TestDrawOrientedSquare checks that DrawLandscape rotates on a square
+// panel while a concurrent DrawImage of the same image does not.
+func TestDrawOrientedSquare(t *testing.T) {
+	config := DefaultConfig()
+	config.CustomWidth, config.CustomHeight = 128, 128
+	config.RotationMode = RotationSoftware
+	d := newDryRunDisplay(t, config)
+
+	img := image.NewGray(image.Rect(0, 0, 128, 128))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	img.SetGray(0, 0, color.Gray{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := d.DrawLandscape(img); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := d.DrawImage(img); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, tc := range []struct {
+		name string
+		draw func(image.Image) error
+		x, y int
+	}{
+		{"DrawImage", d.DrawImage, 0, 0},
+		{"DrawLandscape", d.DrawLandscape, 0, 127},
+	} {
+		if err := tc.draw(img); err != nil {
+			t.Fatal(err)
+		}
+		if wantBit(d, d.frame, tc.x, tc.y) {
+			t.Errorf("%s: pixel (%d,%d) is white, want the black corner there", tc.name, tc.x, tc.y)
+		}
+	}
+}
+
+// BenchmarkLandscapeRotation compares drawing a landscape image through the
+// SSD1680 column writes (hardware) with rotating it in memory (software).
+func BenchmarkLandscapeRotation(b *testing.B) {
+	for _, mode := range []struct {
+		name string
+		mode RotationMode
+	}{
+		{"Hardware", RotationHardware},
+		{"Software", RotationSoftware},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			config := DefaultConfig()
+			config.DryRun = true
+			config.Logger = discardLogger()
+			config.RotationMode = mode.mode
+			d, err := NewWithConfig(config)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer d.Close()
+
+			w, h := d.Size()
+			img := image.NewRGBA(image.Rect(0, 0, h, w))
+			for y := 0; y < w; y++ {
+				for x := 0; x < h; x++ {
+					if (x/8+y/8)%2 == 0 {
+						img.Set(x, y, color.Black)
+					} else {
+						img.Set(x, y, color.White)
+					}
+				}
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := d.DrawImage(img); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// TestLandscapeRecordedOnWrite checks that only showing a frame records its
+// orientation, not encoding or staging it.
+func TestLandscapeRecordedOnWrite(t *testing.T) {
+	d := newDryRunDisplay(t, DefaultConfig())
+	portrait := whiteImage(d)
+	landscape := image.NewGray(image.Rect(0, 0, d.height, d.width))
+	draw.Draw(landscape, landscape.Bounds(), image.White, image.Point{}, draw.Src)
+
+	if err := d.DrawImage(portrait); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.EncodeForDisplay(landscape); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetImage(landscape); err != nil {
+		t.Fatal(err)
+	}
+	if d.landscape {
+		t.Fatal("encoding and staging a landscape image marked the portrait frame landscape")
+	}
+	if err := d.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if !d.landscape {
+		t.Error("showing the staged landscape image did not record its orientation")
+	}
+	if err := <-d.DrawImageAsync(portrait); err != nil {
+		t.Fatal(err)
+	}
+	if d.landscape {
+		t.Error("showing a portrait image kept the landscape orientation")
+	}
+}
+
+// TestLandscapeColumnsMatchSoftware checks that column writes retain the
+// same frame as software rotation, for gray and non-gray sources.
+func TestLandscapeColumnsMatchSoftware(t *testing.T) {
+	frames := map[RotationMode][]byte{}
+	for _, mode := range []RotationMode{RotationSoftware, RotationHardware} {
+		config := DefaultConfig()
+		config.RotationMode = mode
+		d := newDryRunDisplay(t, config)
+
+		gray := image.NewGray(image.Rect(0, 0, d.height, d.width))
+		for y := 0; y < d.width; y++ {
+			for x := 0; x < d.height; x++ {
+				gray.SetGray(x, y, color.Gray{Y: uint8((x*7 + y*13) % 256)})
+			}
+		}
+		rgba := image.NewRGBA(gray.Bounds())
+		draw.Draw(rgba, rgba.Bounds(), gray, image.Point{}, draw.Src)
+
+		for _, img := range []image.Image{gray, rgba} {
+			if err := d.DrawImage(img); err != nil {
+				t.Fatal(err)
+			}
+			if want, ok := frames[RotationSoftware]; ok && string(d.frame) != string(want) {
+				t.Errorf("%T: column writes retained a different frame than software rotation", img)
+			}
+			frames[mode] = append([]byte(nil), d.frame...)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package epd
+
+import (
+	"errors"
+	"image"
+)
+
+// Screen is one view of an application UI. Render draws the complete view
+// onto a blank portrait canvas and is called on every refresh.
+type Screen interface {
+	Render(c *Canvas)
+}
+
+// ErrNoScreen is returned when navigating or refreshing without a screen
+// on the stack.
+var ErrNoScreen = errors.New("no screen to show")
+
+// ScreenManager keeps a stack of screens and refreshes the display as the
+// user navigates. Changing screens does a full refresh; Refresh redraws the
+// current screen with a partial refresh of only the pixels that changed.
+type ScreenManager struct {
+	d     *Display
+	stack []Screen
+	shown *image.Gray
+}
+
+// NewScreenManager returns a manager with an empty stack for d.
+func NewScreenManager(d *Display) *ScreenManager {
+	return &ScreenManager{d: d}
+}
+
+// Current returns the screen on top of the stack, or nil if it is empty.
+func (m *ScreenManager) Current() Screen {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	return m.stack[len(m.stack)-1]
+}
+
+// Push shows s on top of the current screen.
+func (m *ScreenManager) Push(s Screen) error {
+	m.stack = append(m.stack, s)
+	return m.show()
+}
+
+// Pop returns to the previous screen. The last screen is never popped, so
+// the display always has something to show.
+func (m *ScreenManager) Pop() error {
+	if len(m.stack) < 2 {
+		return ErrNoScreen
+	}
+	m.stack = m.stack[:len(m.stack)-1]
+	return m.show()
+}
+
+// Replace swaps the current screen for s without growing the stack.
+func (m *ScreenManager) Replace(s Screen) error {
+	if len(m.stack) == 0 {
+		return m.Push(s)
+	}
+	m.stack[len(m.stack)-1] = s
+	return m.show()
+}
+
+// Refresh re-renders the current screen and partially refreshes the
+// changed area. Nothing is sent when the render is unchanged, and models
+// without partial refresh fall back to a full refresh.
+func (m *ScreenManager) Refresh() error {
+	s := m.Current()
+	if s == nil {
+		return ErrNoScreen
+	}
+	if m.shown == nil || !m.d.Capabilities().PartialRefresh {
+		return m.show()
+	}
+
+	c := m.render(s)
+	changed := diffRect(m.shown, c.Gray)
+	if changed.Empty() {
+		return nil
+	}
+	if err := m.d.UpdateRegion(changed, c.Gray); err != nil {
+		return err
+	}
+	m.shown = c.Gray
+	return nil
+}
+
+func (m *ScreenManager) show() error {
+	c := m.render(m.Current())
+	m.shown = nil
+	if err := m.d.DrawPortrait(c.Gray); err != nil {
+		return err
+	}
+	m.shown = c.Gray
+	return nil
+}
+
+func (m *ScreenManager) render(s Screen) *Canvas {
+	c := newCanvas(m.d.RequiredBounds())
+	s.Render(c)
+	return c
+}
+
+// diffRect returns the bounding box of the pixels that differ between two
+// images of the same bounds.
+func diffRect(a, b *image.Gray) image.Rectangle {
+	var r image.Rectangle
+	bounds := a.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if a.GrayAt(x, y) != b.GrayAt(x, y) {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return r
+}
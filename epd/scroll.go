@@ -0,0 +1,38 @@
+package epd
+
+import (
+	"image"
+	"image/draw"
+)
+
+// ExtractWindow returns a panel-sized view of src starting at horizontal
+// offset, wrapping back to the left edge of src for seamless loops. A source
+// wider than it is tall yields a landscape window. Rows beyond the height of
+// src are left white.
+func (d *Display) ExtractWindow(src image.Image, offset int) image.Image {
+	bounds := src.Bounds()
+	w, h := d.targetSize(bounds.Dx(), bounds.Dy())
+	window := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(window, window.Bounds(), image.White, image.Point{}, draw.Src)
+
+	srcWidth := bounds.Dx()
+	if srcWidth == 0 {
+		return window
+	}
+	offset %= srcWidth
+	if offset < 0 {
+		offset += srcWidth
+	}
+
+	for x := 0; x < w; {
+		sx := (offset + x) % srcWidth
+		n := srcWidth - sx
+		if n > w-x {
+			n = w - x
+		}
+		dst := image.Rect(x, 0, x+n, h)
+		draw.Draw(window, dst, src, image.Pt(bounds.Min.X+sx, bounds.Min.Y), draw.Src)
+		x += n
+	}
+	return window
+}
@@ -0,0 +1,117 @@
+package epd
+
+import (
+	"fmt"
+	"image"
+)
+
+// Segment bits, in the usual a-g naming: a top, b top right, c bottom
+// right, d bottom, e bottom left, f top left, g middle.
+const (
+	segA = 1 << iota
+	segB
+	segC
+	segD
+	segE
+	segF
+	segG
+)
+
+var sevenSegmentDigits = map[rune]int{
+	'0': segA | segB | segC | segD | segE | segF,
+	'1': segB | segC,
+	'2': segA | segB | segD | segE | segG,
+	'3': segA | segB | segC | segD | segG,
+	'4': segB | segC | segF | segG,
+	'5': segA | segC | segD | segF | segG,
+	'6': segA | segC | segD | segE | segF | segG,
+	'7': segA | segB | segC,
+	'8': segA | segB | segC | segD | segE | segF | segG,
+	'9': segA | segB | segC | segD | segF | segG,
+	'-': segG,
+	' ': 0,
+}
+
+// Glyph geometry in units of scale: segments are 1 thick and 4 long.
+const (
+	sevenSegLength = 4
+	sevenSegWidth  = sevenSegLength + 2
+	sevenSegHeight = 2*sevenSegLength + 3
+	sevenSegGap    = 2
+)
+
+// DrawSevenSegment draws value as 7-segment digits with its top-left corner
+// at at, in portrait panel coordinates, and shows the result with a full
+// refresh. Digits, space, '-', ':' and '.' are supported; any other
+// character is an error. A digit is 6*scale by 11*scale pixels.
+func (d *Display) DrawSevenSegment(value string, at image.Point, scale int) error {
+	bm, err := sevenSegmentBitmap(value, scale)
+	if err != nil {
+		return err
+	}
+	return d.drawBitmap(bm, at)
+}
+
+func sevenSegmentBitmap(value string, scale int) (bitmap, error) {
+	if scale < 1 {
+		return bitmap{}, fmt.Errorf("invalid seven-segment scale %d", scale)
+	}
+
+	var rects []image.Rectangle
+	x := 0
+	for _, r := range value {
+		if x > 0 {
+			x += sevenSegGap
+		}
+		switch r {
+		case ':':
+			third := sevenSegHeight / 3
+			rects = append(rects, image.Rect(x, third, x+1, third+1), image.Rect(x, 2*third, x+1, 2*third+1))
+			x++
+		case '.':
+			rects = append(rects, image.Rect(x, sevenSegHeight-1, x+1, sevenSegHeight))
+			x++
+		default:
+			segs, ok := sevenSegmentDigits[r]
+			if !ok {
+				return bitmap{}, fmt.Errorf("unsupported seven-segment character %q", r)
+			}
+			rects = append(rects, sevenSegments(x, segs)...)
+			x += sevenSegWidth
+		}
+	}
+
+	bm := bitmap{width: x * scale, height: sevenSegHeight * scale}
+	bm.stride = (bm.width + 7) / 8
+	bm.bits = make([]byte, bm.stride*bm.height)
+	for _, r := range rects {
+		r = image.Rect(r.Min.X*scale, r.Min.Y*scale, r.Max.X*scale, r.Max.Y*scale)
+		for py := r.Min.Y; py < r.Max.Y; py++ {
+			for px := r.Min.X; px < r.Max.X; px++ {
+				bm.bits[py*bm.stride+px/8] |= 1 << uint(7-px%8)
+			}
+		}
+	}
+	return bm, nil
+}
+
+// sevenSegments returns the lit segments of a digit at x, in scale units.
+func sevenSegments(x, segs int) []image.Rectangle {
+	const l = sevenSegLength
+	all := [...]image.Rectangle{
+		image.Rect(1, 0, 1+l, 1),         // a
+		image.Rect(1+l, 1, 2+l, 1+l),     // b
+		image.Rect(1+l, 2+l, 2+l, 2+2*l), // c
+		image.Rect(1, 2+2*l, 1+l, 3+2*l), // d
+		image.Rect(0, 2+l, 1, 2+2*l),     // e
+		image.Rect(0, 1, 1, 1+l),         // f
+		image.Rect(1, 1+l, 1+l, 2+l),     // g
+	}
+	var out []image.Rectangle
+	for i, r := range all {
+		if segs&(1<<i) != 0 {
+			out = append(out, r.Add(image.Pt(x, 0)))
+		}
+	}
+	return out
+}
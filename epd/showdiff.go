@@ -0,0 +1,59 @@
+package epd
+
+import "image"
+
+// showDiff is the ShowDiff debug aid: it partially refreshes the bounding
+// box of the pixels img changes, inverted, so the region the driver
+// considers dirty is visible before the real frame replaces it. It does
+// nothing without a previous frame or partial refresh support.
+func (d *Display) showDiff(img image.Image, o orientation) error {
+	if !d.Capabilities().PartialRefresh {
+		return nil
+	}
+	next, _, err := d.encodeOriented(img, o)
+	if err != nil {
+		return err
+	}
+
+	return d.withRecovery(func() error {
+		if d.frame == nil {
+			return nil
+		}
+		changed := d.frameDiff(d.frame, next)
+		if changed.Empty() {
+			return nil
+		}
+
+		lineWidth := d.LineWidth()
+		highlight := append([]byte(nil), d.frame...)
+		for y := changed.Min.Y; y < changed.Max.Y; y++ {
+			row := y
+			if d.config.VerticalFlip {
+				row = d.height - 1 - y
+			}
+			for x := changed.Min.X / 8; x < changed.Max.X/8; x++ {
+				highlight[row*lineWidth+x] = ^next[row*lineWidth+x]
+			}
+		}
+		return d.refreshRegionLocked(changed, highlight)
+	})
+}
+
+// frameDiff returns the byte-aligned bounding box of the bytes that differ
+// between two frame buffers, in portrait panel coordinates.
+func (d *Display) frameDiff(a, b []byte) image.Rectangle {
+	lineWidth := d.LineWidth()
+	var r image.Rectangle
+	for row := 0; row < d.height; row++ {
+		y := row
+		if d.config.VerticalFlip {
+			y = d.height - 1 - row
+		}
+		for x := 0; x < lineWidth; x++ {
+			if a[row*lineWidth+x] != b[row*lineWidth+x] {
+				r = r.Union(image.Rect(x*8, y, x*8+8, y+1))
+			}
+		}
+	}
+	return r
+}
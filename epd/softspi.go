@@ -0,0 +1,144 @@
+package epd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+)
+
+// softSPIPort bit-bangs SPI over two GPIOs. CS and DC are already driven by
+// the Display, so only the clock and data lines are handled here.
+type softSPIPort struct {
+	sck   gpio.PinOut
+	mosi  gpio.PinOut
+	delay time.Duration
+}
+
+func newSoftSPIPort(config DisplayConfig) (*softSPIPort, error) {
+	sck := gpioreg.ByName(config.SCKPin)
+	mosi := gpioreg.ByName(config.MOSIPin)
+	if sck == nil || mosi == nil {
+		return nil, fmt.Errorf("software SPI pins not found: SCK %q, MOSI %q", config.SCKPin, config.MOSIPin)
+	}
+	return &softSPIPort{sck: sck, mosi: mosi, delay: config.SoftSPIDelay}, nil
+}
+
+func (p *softSPIPort) String() string {
+	return "softspi"
+}
+
+func (p *softSPIPort) Close() error {
+	return nil
+}
+
+func (p *softSPIPort) LimitSpeed(f physic.Frequency) error {
+	return nil
+}
+
+func (p *softSPIPort) Connect(f physic.Frequency, mode spi.Mode, bits int) (spi.Conn, error) {
+	c := &softSPIConn{
+		port:     p,
+		cpol:     mode&spi.Mode2 != 0,
+		cpha:     mode&spi.Mode1 != 0,
+		lsbFirst: mode&spi.LSBFirst != 0,
+	}
+	if err := p.sck.Out(c.idle()); err != nil {
+		return nil, fmt.Errorf("software SPI clock init failed: %w", err)
+	}
+	if err := p.mosi.Out(gpio.Low); err != nil {
+		return nil, fmt.Errorf("software SPI data init failed: %w", err)
+	}
+	return c, nil
+}
+
+type softSPIConn struct {
+	port     *softSPIPort
+	cpol     bool
+	cpha     bool
+	lsbFirst bool
+}
+
+func (c *softSPIConn) String() string {
+	return "softspi"
+}
+
+func (c *softSPIConn) Duplex() conn.Duplex {
+	return conn.Half
+}
+
+func (c *softSPIConn) idle() gpio.Level {
+	return gpio.Level(c.cpol)
+}
+
+func (c *softSPIConn) Tx(w, r []byte) error {
+	if len(r) != 0 {
+		return errors.New("software SPI does not support reads")
+	}
+	for _, b := range w {
+		for i := 0; i < 8; i++ {
+			bit := b&(0x80>>uint(i)) != 0
+			if c.lsbFirst {
+				bit = b&(1<<uint(i)) != 0
+			}
+			if err := c.clockBit(gpio.Level(bit)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// clockBit shifts out one bit. With CPHA=0 data is set up before the
+// leading edge; with CPHA=1 it changes on the leading edge and is sampled on
+// the trailing one.
+func (c *softSPIConn) clockBit(bit gpio.Level) error {
+	idle := c.idle()
+	if !c.cpha {
+		if err := c.port.mosi.Out(bit); err != nil {
+			return err
+		}
+		c.wait()
+	}
+	if err := c.port.sck.Out(!idle); err != nil {
+		return err
+	}
+	if c.cpha {
+		if err := c.port.mosi.Out(bit); err != nil {
+			return err
+		}
+	}
+	c.wait()
+	if err := c.port.sck.Out(idle); err != nil {
+		return err
+	}
+	if c.cpha {
+		c.wait()
+	}
+	return nil
+}
+
+// wait spins for the configured half-period; time.Sleep is far too coarse
+// for clock delays in the microsecond range.
+func (c *softSPIConn) wait() {
+	if c.port.delay <= 0 {
+		return
+	}
+	start := time.Now()
+	for time.Since(start) < c.port.delay {
+	}
+}
+
+func (c *softSPIConn) TxPackets(p []spi.Packet) error {
+	for _, pkt := range p {
+		if err := c.Tx(pkt.W, pkt.R); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+)
+
+func (d *Display) DrawImageAt(img image.Image, at image.Point) error {
+	return d.DrawImageAtKeyed(img, at, nil)
+}
+
+func (d *Display) DrawImageAtKeyed(img image.Image, at image.Point, key color.Color) error {
+	return d.withRecovery(func() error {
+		buf := d.retainedCopy()
+		d.blit(buf, img, at, key)
+		return d.writeFrame(buf, d.landscape)
+	})
+}
+
+func (d *Display) drawComposited(img image.Image, o orientation) error {
+	src, landscape, err := d.orientImage(img, o)
+	if err != nil {
+		return err
+	}
+	return d.withRecovery(func() error {
+		buf := d.retainedCopy()
+		d.blit(buf, src, image.Point{}, nil)
+		return d.writeFrame(buf, landscape)
+	})
+}
+
+func (d *Display) blit(buf []byte, img image.Image, at image.Point, key color.Color) {
+	var kr, kg, kb, ka uint32
+	if key != nil {
+		kr, kg, kb, ka = key.RGBA()
+	}
+
+	lineWidth := d.LineWidth()
+	palette := color.Palette{color.Black, color.White}
+	bounds := img.Bounds()
+	for sy := bounds.Min.Y; sy < bounds.Max.Y; sy++ {
+		y := at.Y + sy - bounds.Min.Y
+		if y < 0 || y >= d.height {
+			continue
+		}
+		for sx := bounds.Min.X; sx < bounds.Max.X; sx++ {
+			x := at.X + sx - bounds.Min.X
+			if x < 0 || x >= d.width {
+				continue
+			}
+
+			c := img.At(sx, sy)
+			r, g, b, a := c.RGBA()
+			if a == 0 {
+				continue
+			}
+			if key != nil && r == kr && g == kg && b == kb && a == ka {
+				continue
+			}
+
+			var white bool
+			if d.config.PixelMapper != nil {
+				white = !d.config.PixelMapper(x, y, c)
+			} else {
+				white = palette.Index(c) == 1
+			}
+
+			row := y
+			if d.config.VerticalFlip {
+				row = d.height - 1 - y
+			}
+			mask := byte(1 << uint(7-x%8))
+			if white {
+				buf[x/8+row*lineWidth] |= mask
+			} else {
+				buf[x/8+row*lineWidth] &^= mask
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestComposeConcurrent checks that the operations drawing over the
+// retained frame read and write it under the display lock. Run with -race:
+// the first round races them against full draws, the second checks that
+// concurrent composites do not lose each other's rows.
+func TestComposeConcurrent(t *testing.T) {
+	d := newDryRunDisplay(t, DefaultConfig())
+	white := whiteImage(d)
+	row := image.NewGray(image.Rect(0, 0, 8, 1))
+
+	composers := []func(y int) error{
+		func(y int) error { return d.DrawImageAt(row, image.Pt(0, y)) },
+		func(y int) error {
+			return d.DrawXBM([]byte("#define r_width 8\n#define r_height 1\n{0xFF}"), image.Pt(0, y))
+		},
+		func(y int) error {
+			return d.Batch(func(dst draw.Image) error {
+				draw.Draw(dst, image.Rect(0, y, 8, y+1), image.Black, image.Point{}, draw.Src)
+				return nil
+			})
+		},
+		func(y int) error {
+			d.DrawLine(0, y, 7, y, color.Black)
+			return d.Flush()
+		},
+	}
+	readers := []func() error{
+		d.Redraw,
+		func() error { return d.ShowToast("hi", time.Millisecond) },
+		func() error { d.LastRefreshDuration(); d.DirtyRect(); return nil },
+	}
+
+	run := func(withDraws bool) {
+		var wg sync.WaitGroup
+		for i, compose := range composers {
+			for j := 0; j < 4; j++ {
+				wg.Add(1)
+				go func(y int, compose func(int) error) {
+					defer wg.Done()
+					if err := compose(y); err != nil {
+						t.Error(err)
+					}
+				}(i*4+j, compose)
+			}
+		}
+		for _, read := range readers {
+			wg.Add(1)
+			go func(read func() error) {
+				defer wg.Done()
+				if err := read(); err != nil {
+					t.Error(err)
+				}
+			}(read)
+		}
+		if withDraws {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := d.DrawImage(white); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	if err := d.DrawImage(white); err != nil {
+		t.Fatal(err)
+	}
+	run(true)
+	if err := d.DrawImage(white); err != nil {
+		t.Fatal(err)
+	}
+	run(false)
+
+	for y := 0; y < len(composers)*4; y++ {
+		if wantBit(d, d.frame, 0, y) {
+			t.Errorf("row %d is white, a concurrent composite was lost", y)
+		}
+	}
+}
@@ -0,0 +1,509 @@
+package epd
+
+import (
+	"fmt"
+	"image"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+const (
+	cmdSoftwareReset         byte = 0x12
+	cmdDriverOutputControl   byte = 0x01
+	cmdDataEntryMode         byte = 0x11
+	cmdSetRamXStartEndPos    byte = 0x44
+	cmdSetRamYStartEndPos    byte = 0x45
+	cmdSetRamXCounter        byte = 0x4E
+	cmdSetRamYCounter        byte = 0x4F
+	cmdBorderWaveformControl byte = 0x3C
+	cmdDisplayUpdateControl1 byte = 0x21
+	cmdDisplayUpdateControl2 byte = 0x22
+	cmdTempSensorControl     byte = 0x18
+	cmdWriteRAM              byte = 0x24
+	cmdWriteOldRAM           byte = 0x26
+	cmdEnterDeepSleep        byte = 0x10
+	cmdStatusBitRead         byte = 0x2F
+	cmdGateDrivingVoltage    byte = 0x03
+	cmdSourceDrivingVoltage  byte = 0x04
+	cmdWriteVCOM             byte = 0x2C
+
+	dataEntryX                      byte = 0x03
+	dataEntryAM                     byte = 0x04
+	displayUpdateSequence           byte = 0x20
+	displayUpdateSequenceNormalMode byte = 0xF7
+	displayUpdateSequencePartial    byte = 0xFF
+	displayUpdateSequencePowerOff   byte = 0x03
+	displayUpdateSequencePowerOn    byte = 0xC0
+	borderWaveformWhite             byte = 0x05
+	borderWaveformBlack             byte = 0x04
+	borderWaveformHiZ               byte = 0xC0
+	updateControlSourceS8ToS167     byte = 0x80
+)
+
+// RAMOption selects how a RAM bank feeds the display on SSD1680 panels
+// (display update control 1). The black/white bank is the one DrawImage
+// writes (0x24). The red bank (0x26) doubles as the previous-frame bank for
+// partial refresh on black/white panels.
+type RAMOption byte
+
+const (
+	// RAMNormal uses the bank content as written.
+	RAMNormal RAMOption = 0x0
+	// RAMBypass ignores the bank and reads it as all zeros.
+	RAMBypass RAMOption = 0x4
+	// RAMInverse uses the bank content inverted.
+	RAMInverse RAMOption = 0x8
+)
+
+type TemperatureSensor byte
+
+const (
+	TemperatureSensorInternal TemperatureSensor = 0x80
+	TemperatureSensorExternal TemperatureSensor = 0x48
+)
+
+type GateScan byte
+
+const (
+	GateScanDefault    GateScan = 0x00
+	GateScanReverse    GateScan = 0x01
+	GateScanInterlaced GateScan = 0x02
+	GateScanShifted    GateScan = 0x04
+)
+
+type BorderColor int
+
+const (
+	BorderWhite BorderColor = iota
+	BorderBlack
+	BorderFloating
+)
+
+func (b BorderColor) String() string {
+	switch b {
+	case BorderWhite:
+		return "white"
+	case BorderBlack:
+		return "black"
+	case BorderFloating:
+		return "floating"
+	default:
+		return fmt.Sprintf("BorderColor(%d)", int(b))
+	}
+}
+
+type DataEntry byte
+
+const (
+	DataEntryXIncYInc DataEntry = iota
+	DataEntryXDecYInc
+	DataEntryXIncYDec
+	DataEntryXDecYDec
+)
+
+const (
+	DataEntryNormal     = DataEntryXIncYInc
+	DataEntryMirrorX    = DataEntryXDecYInc
+	DataEntryMirrorY    = DataEntryXIncYDec
+	DataEntryUpsideDown = DataEntryXDecYDec
+)
+
+func (e DataEntry) mirrorX() bool {
+	return e&DataEntryXDecYInc != 0
+}
+
+func (e DataEntry) mirrorY() bool {
+	return e&DataEntryXIncYDec != 0
+}
+
+type ssd1680 struct {
+	d *Display
+}
+
+func (c *ssd1680) init() error {
+	if err := c.d.reset(); err != nil {
+		return err
+	}
+	if err := c.d.waitBusy(); err != nil {
+		return err
+	}
+	return c.d.runInit(c.initSteps)
+}
+
+func (c *ssd1680) initSteps() ([]InitStep, error) {
+	window, err := c.windowSteps(0, 0, c.d.width-1, c.d.height-1)
+	if err != nil {
+		return nil, err
+	}
+
+	sensor := c.d.config.TemperatureSensor
+	if sensor == 0 {
+		sensor = TemperatureSensorInternal
+	}
+
+	steps := []InitStep{
+		{Command: cmdSoftwareReset, ExpectBusy: true, WaitBusy: true},
+		c.driverOutputStep(),
+		{Command: cmdDataEntryMode, Data: []byte{c.dataEntry(false)}},
+	}
+	steps = append(steps, window...)
+	steps = append(steps, c.cursorSteps(0, 0, c.d.width-1, c.d.height-1)...)
+	steps = append(steps,
+		c.borderStep(c.d.config.Border),
+		c.updateControl1Step(c.d.config.RedRAMOption, c.d.config.BWRAMOption, c.d.config.InvertContent),
+		InitStep{Command: cmdTempSensorControl, Data: []byte{byte(sensor)}, WaitBusy: true},
+	)
+
+	voltages, err := c.d.voltageSteps()
+	if err != nil {
+		return nil, err
+	}
+	return append(steps, voltages...), nil
+}
+
+func (c *ssd1680) vcomStep(value byte) (InitStep, error) {
+	if err := checkVoltage("VCOM", value, 0x08, 0x78); err != nil {
+		return InitStep{}, err
+	}
+	return InitStep{Command: cmdWriteVCOM, Data: []byte{value}}, nil
+}
+
+func (c *ssd1680) gateVoltageStep(value byte) (InitStep, error) {
+	if value != 0x00 {
+		if err := checkVoltage("gate voltage", value, 0x03, 0x17); err != nil {
+			return InitStep{}, err
+		}
+	}
+	return InitStep{Command: cmdGateDrivingVoltage, Data: []byte{value}}, nil
+}
+
+func (c *ssd1680) sourceVoltageStep(v SourceVoltage) (InitStep, error) {
+	if v.VSH1 == 0 || v.VSH2 == 0 || v.VSL == 0 {
+		return InitStep{}, fmt.Errorf("invalid source voltage %+v: VSH1, VSH2 and VSL must all be set", v)
+	}
+	for _, vsh := range []struct {
+		name  string
+		value byte
+	}{{"VSH1", v.VSH1}, {"VSH2", v.VSH2}} {
+		if vsh.value&0x80 != 0 {
+			if err := checkVoltage(vsh.name, vsh.value, 0x8E, 0xCE); err != nil {
+				return InitStep{}, err
+			}
+		} else if err := checkVoltage(vsh.name, vsh.value, 0x23, 0x50); err != nil {
+			return InitStep{}, err
+		}
+	}
+	if err := checkVoltage("VSL", v.VSL, 0x1A, 0x3A); err != nil {
+		return InitStep{}, err
+	}
+	if v.VSL%2 != 0 {
+		return InitStep{}, fmt.Errorf("invalid VSL 0x%02X: must be even", v.VSL)
+	}
+	return InitStep{Command: cmdSourceDrivingVoltage, Data: []byte{v.VSH1, v.VSH2, v.VSL}}, nil
+}
+
+func (c *ssd1680) driverOutputStep() InitStep {
+	gates := c.d.height - 1
+	return InitStep{Command: cmdDriverOutputControl, Data: []byte{
+		byte(gates & 0xFF),
+		byte((gates >> 8) & 0x01),
+		byte(c.d.config.GateScan & 0x07),
+	}}
+}
+
+func (c *ssd1680) dataEntry(columns bool) byte {
+	mode := dataEntryX &^ byte(c.d.config.DataEntry)
+	if columns {
+		mode |= dataEntryAM
+	}
+	return mode
+}
+
+func (c *ssd1680) setDataEntryMode(mode byte) error {
+	if err := c.d.sendCommand(cmdDataEntryMode); err != nil {
+		return err
+	}
+	return c.d.sendData(mode)
+}
+
+func (c *ssd1680) borderStep(border BorderColor) InitStep {
+	value := borderWaveformWhite
+	switch border {
+	case BorderBlack:
+		value = borderWaveformBlack
+	case BorderFloating:
+		value = borderWaveformHiZ
+	}
+	return InitStep{Command: cmdBorderWaveformControl, Data: []byte{value}}
+}
+
+func (c *ssd1680) setBorder(border BorderColor) error {
+	if err := c.d.runSteps([]InitStep{c.borderStep(border)}); err != nil {
+		return err
+	}
+	c.d.border = border
+	return nil
+}
+
+// updateControl1Step builds display update control 1: the red bank option
+// in the high nibble and the black/white bank option in the low nibble of
+// the first byte, and the source output range in the second. Content
+// inversion flips the black/white option between normal and inverse. The
+// border is driven separately by the border waveform, so inverting the
+// content leaves the border color unchanged.
+func (c *ssd1680) updateControl1Step(red, bw RAMOption, invert bool) InitStep {
+	if invert && bw != RAMBypass {
+		bw ^= RAMInverse
+	}
+	ram := byte(red&0x0F)<<4 | byte(bw&0x0F)
+	return InitStep{Command: cmdDisplayUpdateControl1, Data: []byte{ram, updateControlSourceS8ToS167}}
+}
+
+func (c *ssd1680) setInvert(invert bool) error {
+	step := c.updateControl1Step(c.d.config.RedRAMOption, c.d.config.BWRAMOption, invert)
+	if err := c.d.runSteps([]InitStep{step}); err != nil {
+		return err
+	}
+	c.d.inverted = invert
+	return nil
+}
+
+func (c *ssd1680) setRAMOptions(red, bw RAMOption) error {
+	return c.d.runSteps([]InitStep{c.updateControl1Step(red, bw, c.d.config.InvertContent)})
+}
+
+func (c *ssd1680) setWindow(xStart, yStart, xEnd, yEnd int) error {
+	steps, err := c.windowSteps(xStart, yStart, xEnd, yEnd)
+	if err != nil {
+		return err
+	}
+	return c.d.runSteps(steps)
+}
+
+func (c *ssd1680) windowSteps(xStart, yStart, xEnd, yEnd int) ([]InitStep, error) {
+	if err := c.validateWindow(xStart, yStart, xEnd, yEnd); err != nil {
+		return nil, err
+	}
+
+	xStart += c.d.config.OriginX
+	xEnd += c.d.config.OriginX
+	yStart += c.d.config.OriginY
+	yEnd += c.d.config.OriginY
+	if err := c.validateRAMWindow(xEnd, yEnd); err != nil {
+		return nil, err
+	}
+
+	if c.d.config.DataEntry.mirrorX() {
+		xStart, xEnd = xEnd, xStart
+	}
+	if c.d.config.DataEntry.mirrorY() {
+		yStart, yEnd = yEnd, yStart
+	}
+
+	return []InitStep{
+		{Command: cmdSetRamXStartEndPos, Data: []byte{
+			byte((xStart >> 3) & 0xFF),
+			byte((xEnd >> 3) & 0xFF),
+		}},
+		{Command: cmdSetRamYStartEndPos, Data: []byte{
+			byte(yStart & 0xFF),
+			byte((yStart >> 8) & 0xFF),
+			byte(yEnd & 0xFF),
+			byte((yEnd >> 8) & 0xFF),
+		}},
+	}, nil
+}
+
+// validateWindow checks a window in panel coordinates. X may extend into
+// the padding bits of the last byte of a row, since windows are byte-aligned.
+func (c *ssd1680) validateWindow(xStart, yStart, xEnd, yEnd int) error {
+	maxX := c.d.LineWidth()*8 - 1
+	maxY := c.d.height - 1
+	if xStart < 0 || xEnd > maxX || xStart > xEnd {
+		return fmt.Errorf("invalid RAM window x range %d..%d: must satisfy 0 <= start <= end <= %d", xStart, xEnd, maxX)
+	}
+	if yStart < 0 || yEnd > maxY || yStart > yEnd {
+		return fmt.Errorf("invalid RAM window y range %d..%d: must satisfy 0 <= start <= end <= %d", yStart, yEnd, maxY)
+	}
+	return nil
+}
+
+// validateRAMWindow checks the end of a window once the origin is applied
+// against the RAM the controller has.
+func (c *ssd1680) validateRAMWindow(xEnd, yEnd int) error {
+	spec := Models[c.d.config.Model]
+	if xEnd >= spec.MaxWidth || yEnd >= spec.MaxHeight {
+		return fmt.Errorf("RAM window end %d,%d past the %v RAM of %dx%d", xEnd, yEnd, c.d.config.Model, spec.MaxWidth, spec.MaxHeight)
+	}
+	return nil
+}
+
+func (c *ssd1680) setCursor(xStart, yStart, xEnd, yEnd int) error {
+	return c.d.runSteps(c.cursorSteps(xStart, yStart, xEnd, yEnd))
+}
+
+func (c *ssd1680) cursorSteps(xStart, yStart, xEnd, yEnd int) []InitStep {
+	x, y := xStart, yStart
+	if c.d.config.DataEntry.mirrorX() {
+		x = xEnd
+	}
+	if c.d.config.DataEntry.mirrorY() {
+		y = yEnd
+	}
+	x += c.d.config.OriginX
+	y += c.d.config.OriginY
+
+	return []InitStep{
+		{Command: cmdSetRamXCounter, Data: []byte{byte((x >> 3) & 0xFF)}},
+		{Command: cmdSetRamYCounter, Data: []byte{byte(y & 0xFF), byte((y >> 8) & 0xFF)}},
+	}
+}
+
+func (c *ssd1680) update() error {
+	if err := c.settlePower(); err != nil {
+		return err
+	}
+	if err := c.d.sendCommand(cmdDisplayUpdateControl2); err != nil {
+		return err
+	}
+	if err := c.d.sendData(displayUpdateSequenceNormalMode); err != nil {
+		return err
+	}
+	if err := c.d.sendCommand(displayUpdateSequence); err != nil {
+		return err
+	}
+	return c.d.waitBusy()
+}
+
+func (c *ssd1680) beginWriteRAM() error {
+	if err := c.setCursor(0, 0, c.d.width-1, c.d.height-1); err != nil {
+		return err
+	}
+	return c.d.sendCommand(cmdWriteRAM)
+}
+
+func (c *ssd1680) beginWriteOldRAM() error {
+	if err := c.setCursor(0, 0, c.d.width-1, c.d.height-1); err != nil {
+		return err
+	}
+	return c.d.sendCommand(cmdWriteOldRAM)
+}
+
+// beginWriteRedRAM selects the red plane on tri-color variants, which share
+// the old-frame bank address.
+func (c *ssd1680) beginWriteRedRAM() error {
+	return c.beginWriteOldRAM()
+}
+
+func (c *ssd1680) statusCommand() byte {
+	return cmdStatusBitRead
+}
+
+func (c *ssd1680) updatePartial(region image.Rectangle, oldBuf, newBuf []byte) error {
+	region = c.d.physicalRegion(region)
+
+	if err := c.setWindow(region.Min.X, region.Min.Y, region.Max.X-1, region.Max.Y-1); err != nil {
+		return err
+	}
+
+	if err := c.setCursor(region.Min.X, region.Min.Y, region.Max.X-1, region.Max.Y-1); err != nil {
+		return err
+	}
+	if err := c.d.sendCommand(cmdWriteOldRAM); err != nil {
+		return err
+	}
+	if err := c.d.sendDataBulk(oldBuf); err != nil {
+		return err
+	}
+
+	if err := c.setCursor(region.Min.X, region.Min.Y, region.Max.X-1, region.Max.Y-1); err != nil {
+		return err
+	}
+	if err := c.d.sendCommand(cmdWriteRAM); err != nil {
+		return err
+	}
+	if err := c.d.sendDataBulk(newBuf); err != nil {
+		return err
+	}
+
+	if err := c.settlePower(); err != nil {
+		return err
+	}
+	if err := c.d.sendCommand(cmdDisplayUpdateControl2); err != nil {
+		return err
+	}
+	if err := c.d.sendData(displayUpdateSequencePartial); err != nil {
+		return err
+	}
+	if err := c.d.sendCommand(displayUpdateSequence); err != nil {
+		return err
+	}
+	if err := c.d.waitBusy(); err != nil {
+		return err
+	}
+
+	return c.setWindow(0, 0, c.d.width-1, c.d.height-1)
+}
+
+func (c *ssd1680) beginWriteRAMColumns() error {
+	if err := c.setDataEntryMode(c.dataEntry(true)); err != nil {
+		return err
+	}
+	return c.beginWriteRAM()
+}
+
+func (c *ssd1680) endWriteRAMColumns() error {
+	return c.setDataEntryMode(c.dataEntry(false))
+}
+
+func (c *ssd1680) standby() error {
+	if err := c.d.sendCommand(cmdDisplayUpdateControl2); err != nil {
+		return err
+	}
+	if err := c.d.sendData(displayUpdateSequencePowerOff); err != nil {
+		return err
+	}
+	if err := c.d.sendCommand(displayUpdateSequence); err != nil {
+		return err
+	}
+	return c.d.waitBusy()
+}
+
+func (c *ssd1680) powerOn() error {
+	return nil
+}
+
+// settlePower runs the clock and analog power-on part of the update
+// sequence on its own and then waits PowerSettleTime, so the charge pump is
+// stable before the waveform starts. Without a settle time the full
+// sequence powers up and drives the panel in one go.
+func (c *ssd1680) settlePower() error {
+	if c.d.config.PowerSettleTime <= 0 {
+		return nil
+	}
+	if err := c.d.sendCommand(cmdDisplayUpdateControl2); err != nil {
+		return err
+	}
+	if err := c.d.sendData(displayUpdateSequencePowerOn); err != nil {
+		return err
+	}
+	if err := c.d.sendCommand(displayUpdateSequence); err != nil {
+		return err
+	}
+	if err := c.d.waitBusy(); err != nil {
+		return err
+	}
+	c.d.clock.Sleep(c.d.config.PowerSettleTime)
+	return nil
+}
+
+func (c *ssd1680) sleep() error {
+	if err := c.d.sendCommand(cmdEnterDeepSleep); err != nil {
+		return err
+	}
+	return c.d.sendData(0x01)
+}
+
+func (c *ssd1680) busyLevel() gpio.Level {
+	return gpio.High
+}
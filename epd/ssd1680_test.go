@@ -0,0 +1,82 @@
+package epd
+
+import (
+	"image"
+	"image/draw"
+	"strings"
+	"testing"
+)
+
+func TestOriginOffsets(t *testing.T) {
+	config := DefaultConfig()
+	config.OriginX = 8
+	config.OriginY = 3
+	d, rec, _, err := NewRecordingDisplay(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.CloseWithoutSleep()
+
+	ops, err := rec.Ops()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Op{
+		{Command: cmdSetRamXStartEndPos, Data: []byte{0x01, 0x10}},
+		{Command: cmdSetRamYStartEndPos, Data: []byte{0x03, 0x00, 0xFC, 0x00}},
+		{Command: cmdSetRamXCounter, Data: []byte{0x01}},
+		{Command: cmdSetRamYCounter, Data: []byte{0x03, 0x00}},
+	}
+	for i, op := range want {
+		if got := ops[3+i]; got.String() != op.String() {
+			t.Errorf("init op %d: got %v, want %v", 3+i, got, op)
+		}
+	}
+
+	img := whiteImage(d)
+	if err := d.DrawImage(img); err != nil {
+		t.Fatal(err)
+	}
+	rec.Reset()
+	draw.Draw(img, image.Rect(8, 2, 16, 4), image.Black, image.Point{}, draw.Src)
+	if err := d.UpdateRegion(image.Rect(8, 2, 16, 4), img); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.ExpectOps(
+		Op{Command: cmdSetRamXStartEndPos, Data: []byte{0x02, 0x02}},
+		Op{Command: cmdSetRamYStartEndPos, Data: []byte{0x05, 0x00, 0x06, 0x00}},
+		Op{Command: cmdSetRamXCounter, Data: []byte{0x02}},
+		Op{Command: cmdSetRamYCounter, Data: []byte{0x05, 0x00}},
+		Op{Command: cmdWriteOldRAM, Data: []byte{0xFF, 0xFF}},
+		Op{Command: cmdSetRamXCounter, Data: []byte{0x02}},
+		Op{Command: cmdSetRamYCounter, Data: []byte{0x05, 0x00}},
+		Op{Command: cmdWriteRAM, Data: []byte{0x00, 0x00}},
+		Op{Command: cmdDisplayUpdateControl2, Data: []byte{displayUpdateSequencePartial}},
+		Op{Command: displayUpdateSequence},
+		Op{Command: cmdSetRamXStartEndPos, Data: []byte{0x01, 0x10}},
+		Op{Command: cmdSetRamYStartEndPos, Data: []byte{0x03, 0x00, 0xFC, 0x00}},
+	); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOriginRejected(t *testing.T) {
+	for _, tc := range []struct {
+		model Model
+		x, y  int
+		want  string
+	}{
+		{ModelSSD1680, 3, 0, "multiple of 8"},
+		{ModelSSD1680, -8, 0, "negative"},
+		{ModelSSD1680, 56, 0, "past the"},
+		{ModelSSD1680, 0, 47, "past the"},
+		{ModelUC8151, 8, 0, "not supported"},
+	} {
+		config := DefaultConfig()
+		config.Model = tc.model
+		config.OriginX, config.OriginY = tc.x, tc.y
+		if _, err := newDisplay(config); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v origin %d,%d: got %v, want error containing %q", tc.model, tc.x, tc.y, err, tc.want)
+		}
+	}
+}
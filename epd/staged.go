@@ -0,0 +1,107 @@
+package epd
+
+import (
+	"errors"
+	"image"
+)
+
+// ErrNothingStaged is returned by Refresh when no image has been staged
+// with SetImage since the last refresh.
+var ErrNothingStaged = errors.New("no staged image to refresh")
+
+// ErrSuperseded is delivered to a DrawImageAsync caller whose refresh was
+// replaced by a newer one before it started.
+var ErrSuperseded = errors.New("refresh superseded by a newer image")
+
+// asyncJob is a queued DrawImageAsync refresh.
+type asyncJob struct {
+	buf       []byte
+	landscape bool
+	done      chan error
+}
+
+// SetImage encodes img and stages it for the next Refresh without touching
+// the panel. Staging again before Refresh replaces the staged frame, so
+// only the latest image is ever shown.
+func (d *Display) SetImage(img image.Image) error {
+	buf, landscape, err := d.encodeForDisplay(img)
+	if err != nil {
+		return err
+	}
+	d.asyncMu.Lock()
+	d.staged = buf
+	d.stagedLandscape = landscape
+	d.asyncMu.Unlock()
+	return nil
+}
+
+// Refresh shows the image staged by SetImage with a full refresh.
+func (d *Display) Refresh() error {
+	d.asyncMu.Lock()
+	buf, landscape := d.staged, d.stagedLandscape
+	d.staged = nil
+	d.asyncMu.Unlock()
+
+	if buf == nil {
+		return ErrNothingStaged
+	}
+	return d.showFrame(buf, landscape)
+}
+
+// DrawImageAsync encodes img and queues a full refresh on a background
+// goroutine. The returned channel receives the refresh result. At most one
+// refresh waits behind the one in progress: a newer call replaces a queued
+// refresh that has not started, whose channel then receives ErrSuperseded.
+func (d *Display) DrawImageAsync(img image.Image) <-chan error {
+	done := make(chan error, 1)
+	buf, landscape, err := d.encodeForDisplay(img)
+	if err != nil {
+		done <- err
+		return done
+	}
+
+	d.asyncMu.Lock()
+	defer d.asyncMu.Unlock()
+	if d.closed {
+		done <- ErrClosed
+		return done
+	}
+	if d.asyncQueued != nil {
+		d.asyncQueued.done <- ErrSuperseded
+	}
+	d.asyncQueued = &asyncJob{buf: buf, landscape: landscape, done: done}
+	if !d.asyncRunning {
+		d.asyncRunning = true
+		d.asyncWG.Add(1)
+		go d.runAsync()
+	}
+	return done
+}
+
+func (d *Display) runAsync() {
+	defer d.asyncWG.Done()
+	for {
+		d.asyncMu.Lock()
+		job := d.asyncQueued
+		d.asyncQueued = nil
+		if job == nil {
+			d.asyncRunning = false
+			d.asyncMu.Unlock()
+			return
+		}
+		d.asyncMu.Unlock()
+
+		job.done <- d.showFrame(job.buf, job.landscape)
+	}
+}
+
+// stopAsync drops a queued refresh and waits for the one in progress.
+func (d *Display) stopAsync() {
+	d.asyncMu.Lock()
+	if d.asyncQueued != nil {
+		d.asyncQueued.done <- ErrClosed
+		d.asyncQueued = nil
+	}
+	d.asyncMu.Unlock()
+	d.asyncWG.Wait()
+}
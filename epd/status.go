@@ -0,0 +1,62 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+const (
+	statusMargin   = 4
+	statusMaxScale = 4
+)
+
+// ShowStatus draws lines centered on the panel at the largest scale that
+// fits. It uses a partial refresh where the model supports one and a full
+// refresh otherwise.
+func (d *Display) ShowStatus(lines []string) error {
+	bounds := d.RequiredBounds()
+	s := d.supersample()
+	canvas := d.newSupersampledCanvas(bounds)
+	drawCenteredLines(canvas, canvas.Bounds().Inset(statusMargin*s), lines, statusMaxScale*s)
+
+	buf, err := d.encodeImage(d.downsample(canvas))
+	if err != nil {
+		return err
+	}
+	return d.refreshRegionOrFull(bounds, buf)
+}
+
+func fitTextScale(area image.Rectangle, lines []string, maxScale int) int {
+	for scale := maxScale; scale > 1; scale-- {
+		if area.Dy() < len(lines)*lineAdvance*scale {
+			continue
+		}
+		fits := true
+		for _, line := range lines {
+			if w, _ := textSize(line, scale); w > area.Dx() {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return scale
+		}
+	}
+	return 1
+}
+
+func drawCenteredLines(dst draw.Image, area image.Rectangle, lines []string, maxScale int) {
+	if len(lines) == 0 {
+		return
+	}
+
+	scale := fitTextScale(area, lines, maxScale)
+	total := len(lines)*lineAdvance*scale - scale
+	y := area.Min.Y + (area.Dy()-total)/2
+	for _, line := range lines {
+		w, _ := textSize(line, scale)
+		drawText(dst, image.Pt(area.Min.X+(area.Dx()-w)/2, y), line, scale, color.Black)
+		y += lineAdvance * scale
+	}
+}
@@ -0,0 +1,19 @@
+package epd
+
+import "testing"
+
+func TestShowStatusWithoutPartial(t *testing.T) {
+	config := DefaultConfig()
+	config.Model = ModelSSD1680Red
+	d, rec, _, err := NewRecordingDisplay(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.CloseWithoutSleep()
+	rec.Reset()
+
+	if err := d.ShowStatus([]string{"ready"}); err != nil {
+		t.Fatal(err)
+	}
+	expectFullRefresh(t, rec)
+}
@@ -0,0 +1,84 @@
+package epd
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+// streamImage converts img row by row into a single row buffer and sends
+// each row as soon as it is ready, so no whole frame is ever held in
+// memory. That also means nothing is retained: see StreamingWrite for what
+// that disables. It runs under withRecovery.
+func (d *Display) streamImage(img image.Image, o orientation) error {
+	bounds := img.Bounds()
+	width := bounds.Dx()
+	height := bounds.Dy()
+
+	landscape := d.isLandscape(width, height, o)
+	var pixelAt func(x, y int) color.Color
+	if landscape {
+		pixelAt = func(x, y int) color.Color {
+			return img.At(bounds.Min.X+width-1-y, bounds.Min.Y+x)
+		}
+	} else if width == d.width && height == d.height {
+		pixelAt = func(x, y int) color.Color {
+			return img.At(bounds.Min.X+x, bounds.Min.Y+y)
+		}
+	} else {
+		return d.dimensionError(width, height)
+	}
+
+	if err := d.ensureAwake(); err != nil {
+		return err
+	}
+	if err := d.ctrl.beginWriteRAM(); err != nil {
+		return err
+	}
+
+	if err := d.setPin(d.dc, gpio.High); err != nil {
+		return fmt.Errorf("DC pin set failed: %w", err)
+	}
+	if err := d.setPin(d.cs, gpio.Low); err != nil {
+		return fmt.Errorf("CS pin set failed: %w", err)
+	}
+
+	palette := color.Palette{color.Black, color.White}
+	lineWidth := d.LineWidth()
+	row := make([]byte, lineWidth)
+	for y := 0; y < d.height; y++ {
+		for i := range row {
+			row[i] = 0
+		}
+		for x := 0; x < d.width; x++ {
+			c := pixelAt(x, y)
+			var white bool
+			if d.config.PixelMapper != nil {
+				white = !d.config.PixelMapper(x, y, c)
+			} else {
+				white = palette.Index(c) == 1
+			}
+			if white {
+				row[x/8] |= 1 << uint(7-x%8)
+			}
+		}
+		reversePixelBytes(d.config.BitOrder, row)
+		if err := d.txBulk(row); err != nil {
+			if csErr := d.setPin(d.cs, gpio.High); csErr != nil {
+				return fmt.Errorf("%w: row %d transmission failed and CS release failed: %w", ErrFrameNotDisplayed, y, csErr)
+			}
+			return d.abortWrite(fmt.Errorf("row %d transmission failed: %w", y, err))
+		}
+	}
+
+	if err := d.setPin(d.cs, gpio.High); err != nil {
+		return err
+	}
+	d.frame = nil
+	d.landscape = landscape
+	d.pending = nil
+
+	return d.update()
+}
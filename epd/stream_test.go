@@ -0,0 +1,79 @@
+package epd
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"runtime"
+	"testing"
+)
+
+func TestStreamingWrite(t *testing.T) {
+	config := DefaultConfig()
+	config.StreamingWrite = true
+	d, rec, _, err := NewRecordingDisplay(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.CloseWithoutSleep()
+	rec.Reset()
+
+	img := whiteImage(d)
+	draw.Draw(img, image.Rect(0, 0, 8, 2), image.Black, image.Point{}, draw.Src)
+	want, err := d.EncodeForDisplay(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.DrawImage(img); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.ExpectOps(
+		Op{Command: cmdSetRamXCounter, Data: []byte{0x00}},
+		Op{Command: cmdSetRamYCounter, Data: []byte{0x00, 0x00}},
+		Op{Command: cmdWriteRAM, Data: want},
+		Op{Command: cmdDisplayUpdateControl2, Data: []byte{displayUpdateSequenceNormalMode}},
+		Op{Command: displayUpdateSequence},
+	); err != nil {
+		t.Error(err)
+	}
+
+	if err := d.Redraw(); !errors.Is(err, ErrNoFrame) {
+		t.Errorf("Redraw after a streaming write: got %v, want ErrNoFrame", err)
+	}
+	rec.Reset()
+	if err := d.UpdateRegion(image.Rect(0, 0, 8, 2), img); err != nil {
+		t.Fatal(err)
+	}
+	expectFullRefresh(t, rec)
+}
+
+// TestStreamingWriteAllocatesOneRow checks that a streaming write never
+// allocates a buffer anywhere near the size of the frame.
+func TestStreamingWriteAllocatesOneRow(t *testing.T) {
+	config := DefaultConfig()
+	config.StreamingWrite = true
+	d := newDryRunDisplay(t, config)
+	img := whiteImage(d)
+	if err := d.DrawImage(img); err != nil {
+		t.Fatal(err)
+	}
+
+	large := func() uint64 {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		var n uint64
+		for _, class := range stats.BySize {
+			if int(class.Size) > d.BufferSize()/2 {
+				n += class.Mallocs
+			}
+		}
+		return n
+	}
+	before := large()
+	if err := d.DrawImage(img); err != nil {
+		t.Fatal(err)
+	}
+	if n := large() - before; n > 0 {
+		t.Errorf("streaming write made %d allocations over half the %d byte frame, want only %d byte rows", n, d.BufferSize(), d.LineWidth())
+	}
+}
@@ -0,0 +1,69 @@
+package epd
+
+import (
+	"image"
+	"image/draw"
+)
+
+// supersample returns the factor content is rendered at before being
+// downsampled to the panel, at least 1.
+func (d *Display) supersample() int {
+	if d.config.Supersample < 1 {
+		return 1
+	}
+	return d.config.Supersample
+}
+
+// prepareImage runs the steps shared by every full-frame draw before
+// encoding: the preprocessing pipeline, supersample reduction and oversize
+// fitting.
+func (d *Display) prepareImage(img image.Image) image.Image {
+	return d.fitOversize(d.downsample(d.applyPipeline(img)))
+}
+
+// downsample reduces an image rendered at Supersample times the panel size
+// (in either orientation) by box-averaging each block of pixels and then
+// error-diffusing the result to black and white. Images of any other size
+// are returned unchanged.
+func (d *Display) downsample(img image.Image) image.Image {
+	s := d.supersample()
+	if s == 1 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx()%s != 0 || bounds.Dy()%s != 0 {
+		return img
+	}
+	w, h := bounds.Dx()/s, bounds.Dy()/s
+	if tw, th := d.targetSize(w, h); w != tw || h != th {
+		return img
+	}
+
+	src := toGray(img)
+	dst := image.NewGray(image.Rect(0, 0, w, h))
+	area := s * s
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sum := 0
+			for sy := 0; sy < s; sy++ {
+				row := src.Pix[(y*s+sy)*src.Stride+x*s:]
+				for sx := 0; sx < s; sx++ {
+					sum += int(row[sx])
+				}
+			}
+			dst.Pix[y*dst.Stride+x] = uint8(sum / area)
+		}
+	}
+	return StageDither()(dst)
+}
+
+// newSupersampledCanvas returns a white canvas covering bounds at the
+// configured supersample factor, for helpers that rasterize their own
+// content.
+func (d *Display) newSupersampledCanvas(bounds image.Rectangle) *image.Gray {
+	s := d.supersample()
+	canvas := image.NewGray(image.Rect(bounds.Min.X*s, bounds.Min.Y*s, bounds.Max.X*s, bounds.Max.Y*s))
+	draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+	return canvas
+}
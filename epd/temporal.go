@@ -0,0 +1,64 @@
+package epd
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+const temporalGrayCycles = 3
+
+var bayer4 = [4][4]int{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// DrawImageGrayTemporal is experimental. It approximates up to 16 gray levels
+// on a 1-bit panel by cycling levels-1 dithered frames with partial refreshes,
+// repeated a fixed number of times before settling on the last frame. Expect
+// visible flicker while it runs; every cycle costs several refreshes, so heavy
+// use noticeably increases panel wear compared to a single DrawImage.
+func (d *Display) DrawImageGrayTemporal(img image.Image, levels int) error {
+	if levels < 2 || levels > 16 {
+		return fmt.Errorf("invalid gray level count %d: must be between 2 and 16", levels)
+	}
+
+	src, _, err := d.orientImage(img, orientAuto)
+	if err != nil {
+		return err
+	}
+
+	bounds := src.Bounds()
+	quantized := make([]int, d.width*d.height)
+	for y := 0; y < d.height; y++ {
+		for x := 0; x < d.width; x++ {
+			g := color.GrayModel.Convert(src.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+			quantized[y*d.width+x] = (int(g.Y)*(levels-1) + 127) / 255
+		}
+	}
+
+	frames := levels - 1
+	lineWidth := d.LineWidth()
+	region := image.Rect(0, 0, d.width, d.height)
+	for cycle := 0; cycle < temporalGrayCycles; cycle++ {
+		for k := 0; k < frames; k++ {
+			buf := make([]byte, lineWidth*d.height)
+			for y := 0; y < d.height; y++ {
+				for x := 0; x < d.width; x++ {
+					phase := bayer4[y%4][x%4] * frames / 16
+					if (k+phase)%frames < quantized[y*d.width+x] {
+						buf[x/8+y*lineWidth] |= 1 << uint(7-x%8)
+					}
+				}
+			}
+			d.flipVertical(buf)
+			if err := d.refreshRegion(region, buf); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
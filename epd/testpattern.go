@@ -0,0 +1,112 @@
+package epd
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// TestPattern selects a diagnostic image for DrawTestPattern.
+type TestPattern int
+
+const (
+	// TestPatternGrid draws a one-pixel outline and grid lines every 8
+	// pixels, which shows offsets and byte-alignment problems.
+	TestPatternGrid TestPattern = iota
+	// TestPatternColumns alternates black and white one-pixel columns.
+	TestPatternColumns
+	// TestPatternRows alternates black and white one-pixel rows.
+	TestPatternRows
+	// TestPatternCorners marks each corner with a different number of
+	// squares (1 top-left, 2 top-right, 3 bottom-right, 4 bottom-left) to
+	// check orientation and mirroring.
+	TestPatternCorners
+	// TestPatternStaircase draws horizontal bands from white to black,
+	// dithered to 1 bit.
+	TestPatternStaircase
+)
+
+const (
+	testGridSpacing   = 8
+	testMarkerSize    = 6
+	testStaircaseStep = 8
+)
+
+// DrawTestPattern shows a procedurally generated diagnostic pattern at the
+// panel's native portrait size with a full refresh. The configured
+// Pipeline is bypassed so the panel receives the pattern as generated.
+func (d *Display) DrawTestPattern(pattern TestPattern) error {
+	img, err := d.testPattern(pattern)
+	if err != nil {
+		return err
+	}
+	buf, err := d.encodeImage(img)
+	if err != nil {
+		return err
+	}
+	return d.showFrame(buf, false)
+}
+
+func (d *Display) testPattern(pattern TestPattern) (*image.Gray, error) {
+	bounds := d.RequiredBounds()
+	img := image.NewGray(bounds)
+	draw.Draw(img, bounds, image.White, image.Point{}, draw.Src)
+	w, h := d.width, d.height
+
+	switch pattern {
+	case TestPatternGrid:
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				if x%testGridSpacing == 0 || y%testGridSpacing == 0 || x == w-1 || y == h-1 {
+					img.SetGray(x, y, color.Gray{})
+				}
+			}
+		}
+	case TestPatternColumns:
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x += 2 {
+				img.SetGray(x, y, color.Gray{})
+			}
+		}
+	case TestPatternRows:
+		for y := 0; y < h; y += 2 {
+			for x := 0; x < w; x++ {
+				img.SetGray(x, y, color.Gray{})
+			}
+		}
+	case TestPatternCorners:
+		drawRectOutline(img, bounds, color.Black)
+		corners := []struct {
+			at  image.Point
+			dir image.Point
+		}{
+			{image.Pt(2, 2), image.Pt(1, 0)},
+			{image.Pt(w-2-testMarkerSize, 2), image.Pt(-1, 0)},
+			{image.Pt(w-2-testMarkerSize, h-2-testMarkerSize), image.Pt(-1, 0)},
+			{image.Pt(2, h-2-testMarkerSize), image.Pt(1, 0)},
+		}
+		for i, c := range corners {
+			for n := 0; n <= i; n++ {
+				at := c.at.Add(c.dir.Mul(n * (testMarkerSize + 2)))
+				draw.Draw(img, image.Rect(at.X, at.Y, at.X+testMarkerSize, at.Y+testMarkerSize), image.Black, image.Point{}, draw.Src)
+			}
+		}
+	case TestPatternStaircase:
+		steps := h / testStaircaseStep
+		for y := 0; y < h; y++ {
+			level := y / testStaircaseStep
+			if level >= steps {
+				level = steps - 1
+			}
+			v := uint8(255 - level*255/max(steps-1, 1))
+			for x := 0; x < w; x++ {
+				img.SetGray(x, y, color.Gray{Y: v})
+			}
+		}
+		return toGray(StageDither()(img)), nil
+	default:
+		return nil, fmt.Errorf("unknown test pattern %d", pattern)
+	}
+	return img, nil
+}
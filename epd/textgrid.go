@@ -0,0 +1,103 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// TextGrid lays out the built-in 5x7 monospace font on a fixed grid of
+// cells and refreshes only what changed.
+type TextGrid struct {
+	d      *Display
+	scale  int
+	canvas *image.Gray
+	cells  [][]rune
+	dirty  [][]bool
+}
+
+// NewTextGrid creates a grid covering the whole panel in portrait or
+// landscape orientation. Each cell is one glyph advance wide and one line
+// tall at the given scale. All cells start out blank.
+func (d *Display) NewTextGrid(scale int, landscape bool) *TextGrid {
+	if scale < 1 {
+		scale = 1
+	}
+	bounds := d.RequiredBounds()
+	if landscape {
+		bounds = image.Rect(0, 0, d.height, d.width)
+	}
+
+	canvas := image.NewGray(bounds)
+	draw.Draw(canvas, bounds, image.White, image.Point{}, draw.Src)
+
+	rows := bounds.Dy() / (lineAdvance * scale)
+	cols := bounds.Dx() / (glyphAdvance * scale)
+	g := &TextGrid{d: d, scale: scale, canvas: canvas}
+	g.cells = make([][]rune, rows)
+	g.dirty = make([][]bool, rows)
+	for r := range g.cells {
+		g.cells[r] = make([]rune, cols)
+		g.dirty[r] = make([]bool, cols)
+		for c := range g.cells[r] {
+			g.cells[r][c] = ' '
+		}
+	}
+	return g
+}
+
+// Size returns the number of rows and columns in the grid.
+func (g *TextGrid) Size() (rows, cols int) {
+	if len(g.cells) == 0 {
+		return 0, 0
+	}
+	return len(g.cells), len(g.cells[0])
+}
+
+// SetCell places ch at row, col. Out-of-range cells are ignored. Nothing is
+// sent to the panel until Flush.
+func (g *TextGrid) SetCell(row, col int, ch rune) {
+	rows, cols := g.Size()
+	if row < 0 || row >= rows || col < 0 || col >= cols {
+		return
+	}
+	if g.cells[row][col] != ch {
+		g.cells[row][col] = ch
+		g.dirty[row][col] = true
+	}
+}
+
+// Flush redraws the cells changed since the last Flush and partially
+// refreshes the smallest rectangle that covers them.
+func (g *TextGrid) Flush() error {
+	var changed image.Rectangle
+	for r, row := range g.cells {
+		for c, ch := range row {
+			if !g.dirty[r][c] {
+				continue
+			}
+			cell := g.cellRect(r, c)
+			draw.Draw(g.canvas, cell, image.White, image.Point{}, draw.Src)
+			drawText(g.canvas, cell.Min, string(ch), g.scale, color.Black)
+			changed = changed.Union(cell)
+		}
+	}
+	if changed.Empty() {
+		return nil
+	}
+
+	if err := g.d.UpdateRegion(changed, g.canvas); err != nil {
+		return err
+	}
+	for _, row := range g.dirty {
+		for c := range row {
+			row[c] = false
+		}
+	}
+	return nil
+}
+
+func (g *TextGrid) cellRect(row, col int) image.Rectangle {
+	w, h := glyphAdvance*g.scale, lineAdvance*g.scale
+	return image.Rect(col*w, row*h, (col+1)*w, (row+1)*h)
+}
@@ -0,0 +1,61 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+)
+
+// SuggestThreshold picks a black/white cutoff for img with Otsu's method,
+// which maximizes the separation between the dark and light luminance
+// classes. The result is a level for StageThreshold: gray values below it
+// become black. An image with a single gray level yields 128.
+func SuggestThreshold(img image.Image) uint8 {
+	var hist [256]int
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			hist[color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y]++
+		}
+	}
+
+	total := bounds.Dx() * bounds.Dy()
+	var sum float64
+	for v, n := range hist {
+		sum += float64(v * n)
+	}
+
+	var sumDark float64
+	var dark int
+	// Between-class variance is flat across an empty gap in the histogram;
+	// take the middle of the flat run instead of its dark edge.
+	best, bestEnd, bestVar := -1, -1, 0.0
+	for t := 0; t < 255; t++ {
+		dark += hist[t]
+		if dark == 0 {
+			continue
+		}
+		light := total - dark
+		if light == 0 {
+			break
+		}
+		sumDark += float64(t * hist[t])
+		meanDark := sumDark / float64(dark)
+		meanLight := (sum - sumDark) / float64(light)
+		between := float64(dark) * float64(light) * (meanDark - meanLight) * (meanDark - meanLight)
+		if between > bestVar {
+			best, bestEnd, bestVar = t, t, between
+		} else if between == bestVar && bestEnd == t-1 {
+			bestEnd = t
+		}
+	}
+	if best < 0 {
+		return 128
+	}
+	return uint8((best+bestEnd)/2 + 1)
+}
+
+// DrawImageAutoThreshold draws img like DrawImage after converting it to
+// black and white at the level SuggestThreshold picks for it.
+func (d *Display) DrawImageAutoThreshold(img image.Image) error {
+	return d.DrawImage(StageThreshold(SuggestThreshold(img))(img))
+}
@@ -0,0 +1,61 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// grayImage returns a one-row image with the given pixel values.
+func grayImage(values []uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, len(values), 1))
+	copy(img.Pix, values)
+	return img
+}
+
+func TestSuggestThresholdBimodal(t *testing.T) {
+	// Two spread-out clusters, a larger dark one around 40 and a light one
+	// around 200, with nothing in between.
+	var values []uint8
+	for v := 20; v <= 60; v++ {
+		for n := 0; n < 30-abs(v-40); n++ {
+			values = append(values, uint8(v))
+		}
+	}
+	for v := 180; v <= 220; v++ {
+		for n := 0; n < 20-abs(v-200)/2; n++ {
+			values = append(values, uint8(v))
+		}
+	}
+
+	level := SuggestThreshold(grayImage(values))
+	if level <= 60 || level > 180 {
+		t.Fatalf("SuggestThreshold = %d, want a level in 61..180 separating the clusters", level)
+	}
+
+	out := StageThreshold(level)(grayImage(values))
+	for i, v := range values {
+		black := color.GrayModel.Convert(out.At(i, 0)).(color.Gray).Y == 0
+		if black != (v <= 60) {
+			t.Fatalf("value %d thresholded to black=%v at level %d", v, black, level)
+		}
+	}
+}
+
+func TestSuggestThresholdPlateau(t *testing.T) {
+	// Two spikes leave the between-class variance flat for every cutoff in
+	// 40..199; the middle of that run is picked, not its dark edge.
+	values := make([]uint8, 0, 200)
+	for i := 0; i < 100; i++ {
+		values = append(values, 40, 200)
+	}
+	if got, want := SuggestThreshold(grayImage(values)), uint8((40+199)/2+1); got != want {
+		t.Errorf("SuggestThreshold = %d, want %d", got, want)
+	}
+}
+
+func TestSuggestThresholdUniform(t *testing.T) {
+	if got := SuggestThreshold(grayImage([]uint8{90, 90, 90})); got != 128 {
+		t.Errorf("SuggestThreshold of a uniform image = %d, want 128", got)
+	}
+}
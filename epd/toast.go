@@ -0,0 +1,87 @@
+package epd
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"time"
+)
+
+const (
+	toastHeight   = 40
+	toastPadding  = 4
+	toastIconSize = 16
+	toastMaxScale = 2
+)
+
+// ShowToast draws a bordered notification box with an icon and msg over
+// the current frame using a partial refresh, waits dur, then restores the
+// covered area with another partial refresh. The box spans the middle of
+// the portrait panel; msg may contain newlines. A frame must have been
+// drawn first.
+func (d *Display) ShowToast(msg string, dur time.Duration) error {
+	box := d.toastRect()
+
+	canvas := image.NewGray(d.RequiredBounds())
+	draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+	drawRectOutline(canvas, box, color.Black)
+	drawRectOutline(canvas, box.Inset(1), color.Black)
+
+	inner := box.Inset(toastPadding)
+	iconY := inner.Min.Y + (inner.Dy()-toastIconSize)/2
+	icon := image.Rect(inner.Min.X, iconY, inner.Min.X+toastIconSize, iconY+toastIconSize)
+	draw.Draw(canvas, icon, image.Black, image.Point{}, draw.Src)
+	w, h := textSize("!", 2)
+	drawText(canvas, image.Pt(icon.Min.X+(toastIconSize-w)/2, icon.Min.Y+(toastIconSize-h)/2), "!", 2, color.White)
+
+	text := inner
+	text.Min.X = icon.Max.X + toastPadding
+	drawCenteredLines(canvas, text, strings.Split(msg, "\n"), toastMaxScale)
+
+	toast, err := d.encodeImage(canvas)
+	if err != nil {
+		return err
+	}
+	var saved []byte
+	err = d.withRecovery(func() error {
+		if d.frame == nil {
+			return ErrNoFrame
+		}
+		saved = append([]byte(nil), d.frame...)
+		next := append([]byte(nil), saved...)
+		d.copyFrameRect(next, toast, box)
+		return d.refreshRegionLocked(box, next)
+	})
+	if err != nil {
+		return err
+	}
+
+	d.clock.Sleep(dur)
+	return d.refreshRegion(box, saved)
+}
+
+// toastRect returns the toast box, byte aligned horizontally so it can be
+// copied between frames without touching neighbouring pixels.
+func (d *Display) toastRect() image.Rectangle {
+	x0, x1 := 8, (d.width-8)/8*8
+	if x1 <= x0 {
+		x0, x1 = 0, d.width
+	}
+	y0 := (d.height - toastHeight) / 2
+	return image.Rect(x0, y0, x1, y0+toastHeight)
+}
+
+// copyFrameRect copies the byte-aligned rectangle r, in portrait panel
+// coordinates, from src into dst.
+func (d *Display) copyFrameRect(dst, src []byte, r image.Rectangle) {
+	lineWidth := d.LineWidth()
+	x0, x1 := r.Min.X/8, (r.Max.X+7)/8
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := y
+		if d.config.VerticalFlip {
+			row = d.height - 1 - y
+		}
+		copy(dst[row*lineWidth+x0:row*lineWidth+x1], src[row*lineWidth+x0:row*lineWidth+x1])
+	}
+}
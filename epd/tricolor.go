@@ -0,0 +1,77 @@
+package epd
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// redPlaneWriter is implemented by controllers that have a red RAM plane on
+// tri-color panels.
+type redPlaneWriter interface {
+	beginWriteRedRAM() error
+}
+
+// DrawImageTriColor draws a single image on a black/white/red panel. Each
+// pixel goes to whichever of black, white or red it is nearest to, but only
+// pixels whose red channel exceeds both green and blue by at least
+// DisplayConfig.RedThreshold are eligible for red. Models without a red
+// plane return an error.
+func (d *Display) DrawImageTriColor(img image.Image) error {
+	rw, ok := d.ctrl.(redPlaneWriter)
+	if !ok || !d.Capabilities().TriColor {
+		return fmt.Errorf("model %v does not support red", d.config.Model)
+	}
+
+	src, landscape, err := d.orientImage(d.applyPipeline(img), orientAuto)
+	if err != nil {
+		return err
+	}
+
+	black, red := d.triColorPlanes(src)
+	d.flipVertical(black)
+	d.flipVertical(red)
+	return d.withRecovery(func() error {
+		if err := d.ensureAwake(); err != nil {
+			return err
+		}
+		if err := rw.beginWriteRedRAM(); err != nil {
+			return err
+		}
+		if err := d.sendDataBulk(red); err != nil {
+			return d.abortWrite(err)
+		}
+		return d.writeFrame(black, landscape)
+	})
+}
+
+// triColorPlanes splits a portrait image into a black plane (bit 1 = white)
+// and a red plane (bit 1 = red), both in the usual frame layout.
+func (d *Display) triColorPlanes(img image.Image) (black, red []byte) {
+	lineWidth := d.LineWidth()
+	black = make([]byte, d.BufferSize())
+	red = make([]byte, d.BufferSize())
+
+	threshold := int(d.config.RedThreshold)
+	palette := color.Palette{color.Black, color.White, color.RGBA{R: 0xFF, A: 0xFF}}
+	bounds := img.Bounds()
+	for y := 0; y < d.height; y++ {
+		for x := 0; x < d.width; x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			idx := palette[:2].Index(c)
+			if int(c.R)-int(max(c.G, c.B)) >= threshold {
+				idx = palette.Index(c)
+			}
+
+			bit := byte(1 << uint(7-x%8))
+			switch idx {
+			case 1:
+				black[y*lineWidth+x/8] |= bit
+			case 2:
+				black[y*lineWidth+x/8] |= bit
+				red[y*lineWidth+x/8] |= bit
+			}
+		}
+	}
+	return black, red
+}
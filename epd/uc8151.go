@@ -0,0 +1,276 @@
+package epd
+
+import (
+	"fmt"
+	"image"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+const (
+	cmdPanelSetting       byte = 0x00
+	cmdPowerSetting       byte = 0x01
+	cmdPowerOff           byte = 0x02
+	cmdPowerOn            byte = 0x04
+	cmdBoosterSoftStart   byte = 0x06
+	cmdDeepSleep          byte = 0x07
+	cmdDataStartOld       byte = 0x10
+	cmdDisplayRefresh     byte = 0x12
+	cmdDataStartNew       byte = 0x13
+	cmdPLLControl         byte = 0x30
+	cmdGetStatus          byte = 0x71
+	cmdPartialWindow      byte = 0x90
+	cmdPartialIn          byte = 0x91
+	cmdPartialOut         byte = 0x92
+	cmdVCOMDataInterval   byte = 0x50
+	cmdResolutionSetting  byte = 0x61
+	cmdVCOMDCSetting      byte = 0x82
+	deepSleepCheckCode    byte = 0xA5
+	vcomDataIntervalSleep byte = 0xF7
+	vcomDataIntervalBW    byte = 0x97
+	vcomDataBorderMask    byte = 0xC0
+	vcomDataBorderWhite   byte = 0x80
+	vcomDataBorderBlack   byte = 0x40
+	vcomDataBorderHiZ     byte = 0x00
+	vcomDataInvert        byte = 0x10 // toggles DDX[0], the data polarity bit
+
+	panelSettingDefault    byte = 0x1F
+	panelSettingScanUp     byte = 0x08
+	panelSettingShiftRight byte = 0x04
+)
+
+type uc8151 struct {
+	d *Display
+}
+
+// checkUC8151Config rejects the SSD1680-only options, which the UC8151
+// would otherwise silently ignore.
+func checkUC8151Config(config DisplayConfig) error {
+	switch {
+	case config.TemperatureSensor != 0 && config.TemperatureSensor != TemperatureSensorInternal:
+		return fmt.Errorf("temperature sensor selection is not supported on %v", config.Model)
+	case config.GateScan != GateScanDefault:
+		return fmt.Errorf("gate scan options are not supported on %v", config.Model)
+	case config.RedRAMOption != RAMNormal || config.BWRAMOption != RAMNormal:
+		return fmt.Errorf("RAM options are not supported on %v", config.Model)
+	}
+	return nil
+}
+
+func (c *uc8151) init() error {
+	if err := c.d.reset(); err != nil {
+		return err
+	}
+	return c.d.runInit(c.initSteps)
+}
+
+func (c *uc8151) initSteps() ([]InitStep, error) {
+	vcom := InitStep{Command: cmdVCOMDCSetting, Data: []byte{0x28}}
+	if c.d.config.VCOM != 0 {
+		step, err := c.vcomStep(c.d.config.VCOM)
+		if err != nil {
+			return nil, err
+		}
+		vcom = step
+	}
+	if c.d.config.GateVoltage != 0 || c.d.config.SourceVoltage != (SourceVoltage{}) {
+		return nil, ErrVoltageNotSupported
+	}
+
+	return []InitStep{
+		{Command: cmdPowerSetting, Data: []byte{0x03, 0x00, 0x2B, 0x2B, 0x03}},
+		{Command: cmdBoosterSoftStart, Data: []byte{0x17, 0x17, 0x17}},
+		{Command: cmdPowerOn, ExpectBusy: true, WaitBusy: true, Delay: c.d.config.PowerSettleTime},
+		{Command: cmdPanelSetting, Data: []byte{c.panelSetting(), 0x0D}},
+		{Command: cmdPLLControl, Data: []byte{0x3A}},
+		{Command: cmdResolutionSetting, Data: []byte{
+			byte(c.d.LineWidth() * 8),
+			byte((c.d.height >> 8) & 0xFF),
+			byte(c.d.height & 0xFF),
+		}},
+		vcom,
+		c.dataIntervalStep(c.d.config.Border, c.d.config.InvertContent),
+	}, nil
+}
+
+func (c *uc8151) vcomStep(value byte) (InitStep, error) {
+	if err := checkVoltage("VCOM", value, 0x01, 0x3A); err != nil {
+		return InitStep{}, err
+	}
+	return InitStep{Command: cmdVCOMDCSetting, Data: []byte{value}}, nil
+}
+
+// The UC8151 derives its gate and source levels from the power setting
+// command, which also controls the internal supply modes, so only VCOM is
+// exposed.
+func (c *uc8151) gateVoltageStep(byte) (InitStep, error) {
+	return InitStep{}, ErrVoltageNotSupported
+}
+
+func (c *uc8151) sourceVoltageStep(SourceVoltage) (InitStep, error) {
+	return InitStep{}, ErrVoltageNotSupported
+}
+
+// dataIntervalStep builds the VCOM and data interval setting, which holds
+// both the border level and the data polarity. The default byte already has
+// DDX[0] set, so inverting clears it.
+func (c *uc8151) dataIntervalStep(border BorderColor, invert bool) InitStep {
+	value := vcomDataBorderWhite
+	switch border {
+	case BorderBlack:
+		value = vcomDataBorderBlack
+	case BorderFloating:
+		value = vcomDataBorderHiZ
+	}
+	if invert {
+		value |= vcomDataInvert
+	}
+	return InitStep{Command: cmdVCOMDataInterval, Data: []byte{vcomDataIntervalBW&^vcomDataBorderMask ^ value}}
+}
+
+func (c *uc8151) setBorder(border BorderColor) error {
+	if err := c.d.runSteps([]InitStep{c.dataIntervalStep(border, c.d.config.InvertContent)}); err != nil {
+		return err
+	}
+	c.d.border = border
+	return nil
+}
+
+func (c *uc8151) setInvert(invert bool) error {
+	if err := c.d.runSteps([]InitStep{c.dataIntervalStep(c.d.border, invert)}); err != nil {
+		return err
+	}
+	c.d.inverted = invert
+	return nil
+}
+
+func (c *uc8151) panelSetting() byte {
+	setting := panelSettingDefault
+	if c.d.config.DataEntry.mirrorX() {
+		setting &^= panelSettingShiftRight
+	}
+	if c.d.config.DataEntry.mirrorY() {
+		setting &^= panelSettingScanUp
+	}
+	return setting
+}
+
+func (c *uc8151) command(cmd byte, data ...byte) error {
+	if err := c.d.sendCommand(cmd); err != nil {
+		return err
+	}
+	for _, b := range data {
+		if err := c.d.sendData(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *uc8151) statusCommand() byte {
+	return cmdGetStatus
+}
+
+// beginWriteRAM sends the retained frame, which is what the panel shows,
+// as the old data the refresh diffs against, then starts the new data.
+// Without a retained frame, before the first draw or after a streaming
+// write, white is sent one row at a time.
+func (c *uc8151) beginWriteRAM() error {
+	if err := c.d.sendCommand(cmdDataStartOld); err != nil {
+		return err
+	}
+	if c.d.frame != nil {
+		if err := c.d.sendDataBulk(c.d.frame); err != nil {
+			return err
+		}
+	} else {
+		white := make([]byte, c.d.LineWidth())
+		for i := range white {
+			white[i] = 0xFF
+		}
+		for y := 0; y < c.d.height; y++ {
+			if err := c.d.sendDataBulk(white); err != nil {
+				return err
+			}
+		}
+	}
+
+	return c.d.sendCommand(cmdDataStartNew)
+}
+
+func (c *uc8151) update() error {
+	if err := c.d.sendCommand(cmdDisplayRefresh); err != nil {
+		return err
+	}
+	return c.d.waitBusy()
+}
+
+func (c *uc8151) updatePartial(region image.Rectangle, oldBuf, newBuf []byte) error {
+	region = c.d.physicalRegion(region)
+	if err := c.d.sendCommand(cmdPartialIn); err != nil {
+		return err
+	}
+	if err := c.command(cmdPartialWindow,
+		byte(region.Min.X&0xF8),
+		byte((region.Max.X-1)|0x07),
+		byte((region.Min.Y>>8)&0x01),
+		byte(region.Min.Y&0xFF),
+		byte(((region.Max.Y-1)>>8)&0x01),
+		byte((region.Max.Y-1)&0xFF),
+		0x01); err != nil {
+		return err
+	}
+
+	if err := c.d.sendCommand(cmdDataStartOld); err != nil {
+		return err
+	}
+	if err := c.d.sendDataBulk(oldBuf); err != nil {
+		return err
+	}
+	if err := c.d.sendCommand(cmdDataStartNew); err != nil {
+		return err
+	}
+	if err := c.d.sendDataBulk(newBuf); err != nil {
+		return err
+	}
+
+	if err := c.update(); err != nil {
+		return err
+	}
+	return c.d.sendCommand(cmdPartialOut)
+}
+
+func (c *uc8151) standby() error {
+	if err := c.d.sendCommand(cmdPowerOff); err != nil {
+		return err
+	}
+	return c.d.waitBusy()
+}
+
+func (c *uc8151) powerOn() error {
+	if err := c.d.sendCommand(cmdPowerOn); err != nil {
+		return err
+	}
+	if err := c.d.waitBusy(); err != nil {
+		return err
+	}
+	c.d.clock.Sleep(c.d.config.PowerSettleTime)
+	return nil
+}
+
+func (c *uc8151) sleep() error {
+	if err := c.command(cmdVCOMDataInterval, vcomDataIntervalSleep); err != nil {
+		return err
+	}
+	if err := c.d.sendCommand(cmdPowerOff); err != nil {
+		return err
+	}
+	if err := c.d.waitBusy(); err != nil {
+		return err
+	}
+	return c.command(cmdDeepSleep, deepSleepCheckCode)
+}
+
+func (c *uc8151) busyLevel() gpio.Level {
+	return gpio.Low
+}
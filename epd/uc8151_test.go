@@ -0,0 +1,92 @@
+package epd
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"strings"
+	"testing"
+)
+
+func TestUC8151SendsRetainedFrameAsOld(t *testing.T) {
+	config := DefaultConfig()
+	config.Model = ModelUC8151
+	d, rec, _, err := NewRecordingDisplay(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.CloseWithoutSleep()
+
+	img := whiteImage(d)
+	draw.Draw(img, image.Rect(0, 0, 8, 1), image.Black, image.Point{}, draw.Src)
+	if err := d.DrawImage(img); err != nil {
+		t.Fatal(err)
+	}
+	first := append([]byte(nil), d.frame...)
+
+	rec.Reset()
+	if err := d.DrawImage(whiteImage(d)); err != nil {
+		t.Fatal(err)
+	}
+	white := bytes.Repeat([]byte{0xFF}, d.BufferSize())
+	if err := rec.ExpectOps(
+		Op{Command: cmdDataStartOld, Data: first},
+		Op{Command: cmdDataStartNew, Data: white},
+		Op{Command: cmdDisplayRefresh},
+	); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUC8151RejectsSSD1680Options(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		set  func(*DisplayConfig)
+	}{
+		{"temperature sensor", func(c *DisplayConfig) { c.TemperatureSensor = TemperatureSensorExternal }},
+		{"gate scan", func(c *DisplayConfig) { c.GateScan = GateScanInterlaced }},
+		{"RAM options", func(c *DisplayConfig) { c.BWRAMOption = RAMInverse }},
+	} {
+		config := DefaultConfig()
+		config.Model = ModelUC8151
+		tc.set(&config)
+		if _, err := newDisplay(config); err == nil || !strings.Contains(err.Error(), "not supported") {
+			t.Errorf("%s: got %v, want a not supported error", tc.name, err)
+		}
+	}
+}
+
+func TestUC8151PartialWindowMirrored(t *testing.T) {
+	config := DefaultConfig()
+	config.Model = ModelUC8151
+	config.DataEntry = DataEntryUpsideDown
+	d, rec, _, err := NewRecordingDisplay(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.CloseWithoutSleep()
+
+	img := whiteImage(d)
+	if err := d.DrawImage(img); err != nil {
+		t.Fatal(err)
+	}
+	rec.Reset()
+	draw.Draw(img, image.Rect(0, 0, 8, 2), image.Black, image.Point{}, draw.Src)
+	if err := d.UpdateRegion(image.Rect(0, 0, 8, 2), img); err != nil {
+		t.Fatal(err)
+	}
+
+	ops, err := rec.Ops()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, y := d.LineWidth()*8, d.height-2
+	want := Op{Command: cmdPartialWindow, Data: []byte{
+		byte(w - 8), byte(w - 1),
+		byte(y >> 8), byte(y), byte((y + 1) >> 8), byte(y + 1),
+		0x01,
+	}}
+	if len(ops) < 2 || ops[1].String() != want.String() {
+		t.Errorf("got %v, want partial window %v", ops, want)
+	}
+}
@@ -0,0 +1,130 @@
+package epd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SourceVoltage holds the SSD1680 source driving voltages (command 0x04).
+// Safe values are 0x23 (9V) to 0x50 (17V) or 0x8E (2.4V) to 0xCE (8.8V)
+// for VSH1 and VSH2, and even values from 0x1A (-9V) to 0x3A (-17V) for VSL.
+// The command sets all three at once, so either leave the whole struct zero
+// to keep the defaults or set every field.
+type SourceVoltage struct {
+	VSH1 byte
+	VSH2 byte
+	VSL  byte
+}
+
+// ErrVoltageNotSupported is returned when the controller has no setting for
+// the requested voltage.
+var ErrVoltageNotSupported = errors.New("voltage setting not supported on this model")
+
+// voltageController is implemented by controllers whose drive voltages can
+// be tuned. Each method validates the value and returns the step that
+// programs it.
+type voltageController interface {
+	vcomStep(value byte) (InitStep, error)
+	gateVoltageStep(value byte) (InitStep, error)
+	sourceVoltageStep(v SourceVoltage) (InitStep, error)
+}
+
+// voltageSteps returns the steps for every voltage set in the config. Zero
+// values keep the controller's power-on default.
+func (d *Display) voltageSteps() ([]InitStep, error) {
+	var steps []InitStep
+	if d.config.VCOM == 0 && d.config.GateVoltage == 0 && d.config.SourceVoltage == (SourceVoltage{}) {
+		return steps, nil
+	}
+	vc, ok := d.ctrl.(voltageController)
+	if !ok {
+		return nil, ErrVoltageNotSupported
+	}
+
+	if d.config.GateVoltage != 0 {
+		step, err := vc.gateVoltageStep(d.config.GateVoltage)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	if d.config.SourceVoltage != (SourceVoltage{}) {
+		step, err := vc.sourceVoltageStep(d.config.SourceVoltage)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	if d.config.VCOM != 0 {
+		step, err := vc.vcomStep(d.config.VCOM)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// SetVCOM programs the VCOM voltage and keeps it for later re-inits. See
+// the VCOM config field for safe ranges. The new value takes effect on the
+// next refresh.
+func (d *Display) SetVCOM(value byte) error {
+	vc, ok := d.ctrl.(voltageController)
+	if !ok {
+		return ErrVoltageNotSupported
+	}
+	step, err := vc.vcomStep(value)
+	if err != nil {
+		return err
+	}
+	return d.applyVoltage(step, func() { d.config.VCOM = value })
+}
+
+// SetGateVoltage programs the gate driving voltage (VGH) and keeps it for
+// later re-inits. See the GateVoltage config field for safe ranges.
+func (d *Display) SetGateVoltage(value byte) error {
+	vc, ok := d.ctrl.(voltageController)
+	if !ok {
+		return ErrVoltageNotSupported
+	}
+	step, err := vc.gateVoltageStep(value)
+	if err != nil {
+		return err
+	}
+	return d.applyVoltage(step, func() { d.config.GateVoltage = value })
+}
+
+// SetSourceVoltage programs the source driving voltages and keeps them for
+// later re-inits.
+func (d *Display) SetSourceVoltage(v SourceVoltage) error {
+	vc, ok := d.ctrl.(voltageController)
+	if !ok {
+		return ErrVoltageNotSupported
+	}
+	step, err := vc.sourceVoltageStep(v)
+	if err != nil {
+		return err
+	}
+	return d.applyVoltage(step, func() { d.config.SourceVoltage = v })
+}
+
+func (d *Display) applyVoltage(step InitStep, remember func()) error {
+	d.opMu.Lock()
+	defer d.opMu.Unlock()
+
+	if err := d.ensureAwake(); err != nil {
+		return err
+	}
+	if err := d.runSteps([]InitStep{step}); err != nil {
+		return err
+	}
+	remember()
+	return nil
+}
+
+func checkVoltage(name string, value, min, max byte) error {
+	if value < min || value > max {
+		return fmt.Errorf("invalid %s 0x%02X: must be 0x%02X..0x%02X", name, value, min, max)
+	}
+	return nil
+}
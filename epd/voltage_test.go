@@ -0,0 +1,59 @@
+package epd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetVCOMRange(t *testing.T) {
+	for _, tc := range []struct {
+		model     Model
+		value     byte
+		wantError bool
+	}{
+		{ModelUC8151, 0x00, true},
+		{ModelUC8151, 0x01, false},
+		{ModelUC8151, 0x3A, false},
+		{ModelUC8151, 0x3B, true},
+		{ModelSSD1680, 0x07, true},
+		{ModelSSD1680, 0x08, false},
+	} {
+		config := DefaultConfig()
+		config.Model = tc.model
+		d, rec, _, err := NewRecordingDisplay(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec.Reset()
+		err = d.SetVCOM(tc.value)
+		if (err != nil) != tc.wantError {
+			t.Errorf("%v SetVCOM(0x%02X) = %v, want error %v", tc.model, tc.value, err, tc.wantError)
+		}
+		if tc.wantError {
+			if ops, _ := rec.Ops(); len(ops) != 0 {
+				t.Errorf("%v SetVCOM(0x%02X) sent %v after failing validation", tc.model, tc.value, ops)
+			}
+		}
+		d.CloseWithoutSleep()
+	}
+}
+
+func TestSourceVoltageNeedsAllFields(t *testing.T) {
+	d, rec, _, err := NewRecordingDisplay(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.CloseWithoutSleep()
+
+	rec.Reset()
+	if err := d.SetSourceVoltage(SourceVoltage{VSH1: 0x41}); err == nil || !strings.Contains(err.Error(), "must all be set") {
+		t.Errorf("partial SetSourceVoltage = %v, want an error naming all three fields", err)
+	}
+	rec.Reset()
+	if err := d.SetSourceVoltage(SourceVoltage{VSH1: 0x41, VSH2: 0xA8, VSL: 0x32}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.ExpectOps(Op{Command: cmdSourceDrivingVoltage, Data: []byte{0x41, 0xA8, 0x32}}); err != nil {
+		t.Error(err)
+	}
+}